@@ -0,0 +1,269 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/crypto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Sources of a setting, in order of precedence
+const (
+	sourceFlag     = "flag"
+	sourceEnv      = "env"
+	sourceFile     = "file"
+	sourceDefault  = "default"
+	sourcePsiphon  = "psiphon-config" // Limit from the Psiphon network config
+	sourceAutoTune = "auto-tuned"     // Limit derived from host resources
+	sourceSafeMode = "safe-mode"      // Cleared by --safe-mode
+)
+
+const redacted = "[redacted]"
+
+var configOutput string
+
+// Setting is one resolved value shown by 'config show'
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// ConfigShowJSON is the 'config show --output json' document
+type ConfigShowJSON struct {
+	SettingsFile string    `json:"settingsFile,omitempty"`
+	Settings     []Setting `json:"settings"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect station settings",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration and where each value came from",
+	Long: `Resolve the configuration exactly as 'conduit start' would, from flags,
+CONDUIT_* environment variables, the settings file, the Psiphon config and
+defaults, and print each value with its source. Keys and secrets are redacted.
+
+Takes the same flags as 'conduit start'. Nothing is written to the data
+directory, so it is safe to run next to a running station.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runConfigShow,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+
+	// The start flags are added in start.go once they are defined
+	configShowCmd.Flags().StringVarP(&configOutput, "output", "o", "text", "output format: text or json")
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	if configOutput != "text" && configOutput != "json" {
+		return fmt.Errorf("unknown output format %q (use text or json)", configOutput)
+	}
+
+	// Record where each flag was set, applying sources in start's order
+	sources := make(map[string]string)
+	record := func(source string) func(*pflag.Flag) {
+		return func(flag *pflag.Flag) {
+			if _, ok := sources[flag.Name]; !ok {
+				sources[flag.Name] = source
+			}
+		}
+	}
+	cmd.Flags().Visit(record(sourceFlag))
+	if err := applyEnv(cmd); err != nil {
+		return err
+	}
+	cmd.Flags().Visit(record(sourceEnv))
+	settingsPath, err := applyConfigFile(cmd)
+	if err != nil {
+		return err
+	}
+	cmd.Flags().Visit(record(sourceFile))
+
+	opts, err := startOptions(cmd)
+	if err != nil {
+		return err
+	}
+	opts.ReadOnly = true
+	cfg, err := config.LoadOrCreate(opts)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	defer cfg.Close()
+
+	settings := resolvedSettings(cfg, sources)
+
+	if configOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ConfigShowJSON{SettingsFile: settingsPath, Settings: settings})
+	}
+
+	if settingsPath != "" {
+		fmt.Printf("Settings file: %s\n\n", settingsPath)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SETTING\tVALUE\tSOURCE")
+	for _, s := range settings {
+		value := s.Value
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", s.Name, value, s.Source)
+	}
+	return writer.Flush()
+}
+
+// resolvedSettings lists the values in cfg by flag name with their sources
+func resolvedSettings(cfg *config.Config, sources map[string]string) []Setting {
+	psiphonConfig := cfg.PsiphonConfigPath
+	if cfg.PsiphonConfigData != nil {
+		psiphonConfig = "embedded"
+	}
+	bandwidth := strconv.FormatFloat(cfg.BandwidthMbps(), 'f', -1, 64)
+	if cfg.BandwidthBytesPerSecond == 0 {
+		bandwidth = "unlimited"
+	}
+
+	settings := []Setting{
+		{Name: "data-dir", Value: cfg.DataDir},
+		{Name: "psiphon-config", Value: psiphonConfig},
+		{Name: "max-clients", Value: strconv.Itoa(cfg.MaxClients)},
+		{Name: "bandwidth", Value: bandwidth},
+		{Name: "verbose", Value: strconv.Itoa(cfg.Verbosity)},
+		{Name: "stats-file", Value: cfg.StatsFile},
+		{Name: "metrics-addr", Value: cfg.MetricsAddr},
+		{Name: "idle-restart", Value: formatDuration(cfg.IdleRestart)},
+		{Name: "geo", Value: strconv.FormatBool(cfg.GeoEnabled)},
+		{Name: "geo-estimate-unique", Value: strconv.FormatBool(cfg.GeoEstimateUnique)},
+		{Name: "geo-city", Value: strconv.FormatBool(cfg.GeoCity)},
+		{Name: "geo-asn", Value: strconv.FormatBool(cfg.GeoASN)},
+		{Name: "geo-window", Value: formatDuration(cfg.GeoWindow)},
+		{Name: "geo-persist", Value: strconv.FormatBool(cfg.GeoPersist)},
+		{Name: "geo-rate-window", Value: formatDuration(cfg.GeoRateWindow)},
+		{Name: "geo-observations", Value: cfg.GeoObservations},
+		{Name: "log-rate-limit", Value: strconv.Itoa(cfg.LogRateLimit)},
+		{Name: "log-tag", Value: cfg.LogTag},
+		{Name: "log-stats-json", Value: strconv.FormatBool(cfg.LogStatsJSON)},
+		{Name: "log-syslog", Value: strconv.FormatBool(cfg.LogSyslog)},
+		{Name: "syslog-facility", Value: cfg.SyslogFacility},
+		{Name: "syslog-tag", Value: cfg.SyslogTag},
+		{Name: "stats-sink", Value: cfg.StatsSink},
+		{Name: "report-webhook", Value: redactURL(cfg.ReportWebhook)},
+		{Name: "report-secret", Value: redactSecret(cfg.ReportSecret)},
+		{Name: "report-interval", Value: formatDuration(cfg.ReportInterval)},
+		{Name: "notify-url", Value: redactURL(cfg.NotifyURL)},
+		{Name: "reconnect-on-network-change", Value: strconv.FormatBool(cfg.NetworkWatch)},
+		{Name: "pid-file", Value: pidFilePath},
+		{Name: "safe-mode", Value: strconv.FormatBool(cfg.SafeMode)},
+		{Name: "ephemeral", Value: strconv.FormatBool(cfg.Ephemeral)},
+	}
+
+	for i := range settings {
+		s := &settings[i]
+		if source, ok := sources[s.Name]; ok {
+			s.Source = source
+		} else {
+			s.Source = sourceDefault
+		}
+		if cfg.SafeMode && config.SafeModeClears(s.Name) && s.Source != sourceDefault {
+			s.Source = sourceSafeMode
+		}
+	}
+
+	// Unset limits come from the Psiphon config, auto-tuning or the defaults
+	for i := range settings {
+		s := &settings[i]
+		if (s.Name != "max-clients" && s.Name != "bandwidth") || s.Source != sourceDefault {
+			continue
+		}
+		switch {
+		case strings.Contains(cfg.AutoTuned, s.Name+" "):
+			s.Source = sourceAutoTune
+		case s.Name == "max-clients" && cfg.MaxClients != config.DefaultMaxClients,
+			s.Name == "bandwidth" && cfg.BandwidthMbps() != config.DefaultBandwidthMbps:
+			s.Source = sourcePsiphon
+		}
+	}
+
+	// The identity is shown by its public station ID only
+	stationID, source := "", "data-dir"
+	if cfg.KeyPair != nil {
+		stationID, _ = crypto.KeyPairToCurve25519Base64(cfg.KeyPair)
+	}
+	if cfg.Ephemeral {
+		source = "ephemeral"
+	}
+	settings = append(settings,
+		Setting{Name: "station-id", Value: stationID, Source: source},
+		Setting{Name: "private-key", Value: redactSecret(cfg.PrivateKeyBase64), Source: source},
+	)
+	return settings
+}
+
+// formatDuration prints 0 as empty, like an unset duration flag
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// redactSecret hides a non-empty secret
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURL keeps only the scheme and host of a URL, as webhook URLs often
+// carry a token in the path or query
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	if u.User == nil && (u.Path == "" || u.Path == "/") && u.RawQuery == "" {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// doctorProbeURL is fetched to check outbound HTTPS and the clock
+	doctorProbeURL     = "https://psiphon.ca/"
+	doctorProbeTimeout = 10 * time.Second
+
+	// maxClockSkew is how far off the clock can be before TLS and broker
+	// requests start failing
+	maxClockSkew = 5 * time.Minute
+)
+
+// checkStatus is the outcome of one doctor check
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+// checkResult is one line of the doctor checklist
+type checkResult struct {
+	Name   string
+	Status checkStatus
+	Detail string
+	Hint   string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check this host for common problems",
+	Long: `Run a checklist of the things a station needs to start and reach the
+Psiphon network: a valid Psiphon config, a writable data directory, the
+settings file, geo databases, outbound HTTPS and an accurate clock.
+
+Exits with an error if any check fails. Run it with the same --data-dir and
+--psiphon-config you use for 'conduit start'.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&psiphonConfigPath, "psiphon-config", "c", "", "path to Psiphon network config file (JSON)")
+	doctorCmd.Flags().StringVar(&configFile, "config", "", "settings file to check (default: "+config.ConfigFileName+" in the data dir, then /etc/conduit)")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	if err := applyEnv(cmd); err != nil {
+		return err
+	}
+	dataDir := GetDataDir()
+
+	settings, settingsCheck := checkSettingsFile(dataDir)
+	if psiphonConfigPath == "" {
+		psiphonConfigPath = settings["psiphon-config"]
+	}
+
+	checks := []checkResult{
+		settingsCheck,
+		checkPsiphonConfig(psiphonConfigPath),
+		checkDataDir(dataDir),
+		checkRunning(dataDir),
+		checkGeoDatabases(dataDir),
+	}
+	checks = append(checks, checkNetwork()...)
+
+	failed := 0
+	for _, c := range checks {
+		fmt.Printf("[%s] %s: %s\n", c.Status, c.Name, c.Detail)
+		if c.Hint != "" && c.Status != checkPass {
+			fmt.Printf("       %s\n", c.Hint)
+		}
+		if c.Status == checkFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	fmt.Println("All checks passed.")
+	return nil
+}
+
+// checkSettingsFile validates the settings file, if any, and returns its values
+func checkSettingsFile(dataDir string) (map[string]string, checkResult) {
+	result := checkResult{Name: "Settings file"}
+
+	path := configFile
+	if path == "" {
+		path = config.FindConfigFile(dataDir)
+	}
+	if path == "" {
+		result.Status = checkPass
+		result.Detail = "none (using flags, environment and defaults)"
+		return nil, result
+	}
+
+	values, err := config.ReadConfigFile(path)
+	if err != nil {
+		result.Status = checkFail
+		result.Detail = err.Error()
+		result.Hint = "Fix the YAML, or pass --config to use a different file."
+		return nil, result
+	}
+	for name := range values {
+		if name == "config" || name == "help" || startCmd.Flags().Lookup(name) == nil {
+			result.Status = checkFail
+			result.Detail = fmt.Sprintf("%s: unknown setting %q", path, name)
+			result.Hint = "Settings are 'conduit start' flag names, e.g. max-clients."
+			return nil, result
+		}
+	}
+
+	result.Status = checkPass
+	result.Detail = fmt.Sprintf("%s (%d settings)", path, len(values))
+	return values, result
+}
+
+// checkPsiphonConfig checks the config 'conduit start' would use
+func checkPsiphonConfig(path string) checkResult {
+	result := checkResult{Name: "Psiphon config"}
+
+	var data []byte
+	source := path
+	if path != "" {
+		if err := config.ValidatePsiphonConfigPath(path); err != nil {
+			result.Status = checkFail
+			result.Detail = err.Error()
+			result.Hint = "Check the --psiphon-config path and its permissions."
+			return result
+		}
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			result.Status = checkFail
+			result.Detail = fmt.Sprintf("failed to read %s: %v", path, err)
+			return result
+		}
+	} else if config.HasEmbeddedConfig() {
+		data = config.GetEmbeddedPsiphonConfig()
+		source = "embedded"
+	} else {
+		result.Status = checkFail
+		result.Detail = "no --psiphon-config given and no embedded config"
+		result.Hint = "Use an official release (config embedded), or pass --psiphon-config; contact conduit-oss@psiphon.ca for network config."
+		return result
+	}
+
+	var psiphonConfig struct {
+		PropagationChannelId string
+		SponsorId            string
+	}
+	if err := json.Unmarshal(data, &psiphonConfig); err != nil {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("%s is not valid JSON: %v", source, err)
+		result.Hint = "The file must be the JSON network config provided by Psiphon."
+		return result
+	}
+	if psiphonConfig.PropagationChannelId == "" || psiphonConfig.SponsorId == "" {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("%s is missing PropagationChannelId or SponsorId", source)
+		result.Hint = "The file must be the JSON network config provided by Psiphon."
+		return result
+	}
+	if strings.Trim(psiphonConfig.PropagationChannelId, "F") == "" {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("%s has placeholder values from psiphon_config.example.json", source)
+		result.Hint = "Contact conduit-oss@psiphon.ca for real network config values."
+		return result
+	}
+
+	result.Status = checkPass
+	result.Detail = source
+	return result
+}
+
+// checkDataDir checks the data directory is writable, without creating it
+func checkDataDir(dataDir string) checkResult {
+	result := checkResult{Name: "Data directory", Hint: "Fix the permissions on the directory, or choose another with --data-dir."}
+
+	info, err := os.Stat(dataDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		result.Status = checkWarn
+		result.Detail = fmt.Sprintf("%s does not exist yet; it is created on first start", dataDir)
+		result.Hint = "If that's not the directory you use, pass the same --data-dir as 'conduit start'."
+		return result
+	}
+	if err != nil {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("cannot access %s: %v", dataDir, err)
+		return result
+	}
+	if !info.IsDir() {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("%s is not a directory", dataDir)
+		return result
+	}
+	f, err := os.CreateTemp(dataDir, ".doctor-*")
+	if err != nil {
+		result.Status = checkFail
+		result.Detail = fmt.Sprintf("%s is not writable: %v", dataDir, err)
+		return result
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	if _, _, err := config.LoadKey(dataDir); err != nil {
+		result.Status = checkPass
+		result.Detail = fmt.Sprintf("%s (writable, no station key yet; one is created on first start)", dataDir)
+		return result
+	}
+	result.Status = checkPass
+	result.Detail = fmt.Sprintf("%s (writable, station key present)", dataDir)
+	return result
+}
+
+// checkRunning reports whether a conduit already holds the data directory
+func checkRunning(dataDir string) checkResult {
+	result := checkResult{Name: "Running instance", Status: checkPass}
+
+	pid, running := config.DataDirOwner(dataDir)
+	switch {
+	case !running:
+		result.Detail = "none using this data directory"
+	case pid > 0:
+		result.Detail = fmt.Sprintf("conduit is running with this data directory (pid %d)", pid)
+	default:
+		result.Detail = "conduit is running with this data directory"
+	}
+	return result
+}
+
+// checkGeoDatabases checks the GeoLite2 databases used by --geo
+func checkGeoDatabases(dataDir string) checkResult {
+	result := checkResult{Name: "Geo databases"}
+
+	var found []string
+	for _, name := range []string{geo.CountryDatabaseFile, geo.CityDatabaseFile, geo.ASNDatabaseFile} {
+		info, err := os.Stat(filepath.Join(dataDir, name))
+		if err != nil {
+			continue
+		}
+		found = append(found, fmt.Sprintf("%s (%s old)", name, time.Since(info.ModTime()).Round(time.Hour)))
+	}
+
+	if len(found) == 0 {
+		result.Status = checkWarn
+		result.Detail = "not downloaded"
+		result.Hint = "Only needed for --geo; downloaded on first start, which needs access to github.com."
+		return result
+	}
+	result.Status = checkPass
+	result.Detail = strings.Join(found, ", ")
+	return result
+}
+
+// checkNetwork checks outbound HTTPS and compares the local clock to the
+// server's Date header
+func checkNetwork() []checkResult {
+	network := checkResult{Name: "Network"}
+	clock := checkResult{Name: "Clock"}
+
+	client := &http.Client{Timeout: doctorProbeTimeout}
+	start := time.Now()
+	resp, err := client.Head(doctorProbeURL)
+	if err != nil {
+		network.Status = checkFail
+		network.Detail = fmt.Sprintf("cannot reach %s: %v", doctorProbeURL, err)
+		network.Hint = "Check outbound HTTPS (TCP 443) and DNS; the station needs them to reach the Psiphon brokers."
+		clock.Status = checkWarn
+		clock.Detail = "not checked (no network)"
+		return []checkResult{network, clock}
+	}
+	resp.Body.Close()
+	rtt := time.Since(start)
+
+	network.Status = checkPass
+	network.Detail = fmt.Sprintf("reached %s in %s", doctorProbeURL, rtt.Round(time.Millisecond))
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		clock.Status = checkWarn
+		clock.Detail = "not checked (no Date header in response)"
+		return []checkResult{network, clock}
+	}
+	skew := time.Until(serverTime.Add(rtt / 2)).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		clock.Status = checkFail
+		clock.Detail = fmt.Sprintf("off by %s", skew)
+		clock.Hint = "Enable time sync (NTP); broker connections fail with a badly skewed clock."
+		return []checkResult{network, clock}
+	}
+	clock.Status = checkPass
+	clock.Detail = fmt.Sprintf("within %s of server time", maxClockSkew)
+	return []checkResult{network, clock}
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	geoStatsFile string
+	geoWatch     bool
+	geoInterval  time.Duration
+	geoFormat    string
+)
+
+var geoCmd = &cobra.Command{
+	Use:   "geo",
+	Short: "Show client locations from a running station",
+	Long: `Show per-country client stats written by 'conduit start --geo --stats-file'.
+
+With --watch, periodically print only the countries whose client counts
+changed since the previous update.
+
+With --format geojson, print a GeoJSON FeatureCollection with one point per
+country weighted by live clients, for map tools. Countries without a known
+centroid (and relayed clients) are listed under "unmapped".`,
+	RunE: runGeo,
+}
+
+var geoLookupCmd = &cobra.Command{
+	Use:   "lookup <ip>...",
+	Short: "Resolve IPs the way geo tracking does",
+	Long: `Resolve an IP address through the same private-address filter and
+GeoLite2 lookup used for live connections, to debug misclassifications.
+Downloads the country database into the data directory if it is missing.
+The network (ASN and organization) is shown if the GeoLite2-ASN database
+is in the data directory, as it is after running with --geo-asn.
+
+With several IPs, print how many fall in each country and network.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGeoLookup,
+}
+
+func init() {
+	rootCmd.AddCommand(geoCmd)
+	geoCmd.AddCommand(geoLookupCmd)
+
+	geoCmd.PersistentFlags().StringVarP(&geoStatsFile, "stats-file", "s", "stats.json", "stats JSON file written by 'conduit start' (relative to data dir)")
+	geoCmd.Flags().BoolVarP(&geoWatch, "watch", "w", false, "print changes as they happen instead of the full table")
+	geoCmd.Flags().DurationVar(&geoInterval, "interval", 10*time.Second, "how often to check for changes with --watch")
+	geoCmd.Flags().StringVar(&geoFormat, "format", "table", "output format: table or geojson")
+}
+
+// resolveGeoStatsFile returns the stats file path, relative paths being in the data dir
+func resolveGeoStatsFile() string {
+	if filepath.IsAbs(geoStatsFile) {
+		return geoStatsFile
+	}
+	return filepath.Join(GetDataDir(), geoStatsFile)
+}
+
+// readGeoResults reads the geo section of a stats file
+func readGeoResults(path string) ([]geo.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats file: %w", err)
+	}
+	var stats conduit.StatsJSON
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse stats file: %w", err)
+	}
+	return stats.Geo, nil
+}
+
+func runGeo(cmd *cobra.Command, args []string) error {
+	if geoFormat != "table" && geoFormat != "geojson" {
+		return fmt.Errorf("unknown format %q (use table or geojson)", geoFormat)
+	}
+	if geoFormat == "geojson" && geoWatch {
+		return fmt.Errorf("--watch only supports table output")
+	}
+
+	path := resolveGeoStatsFile()
+	results, err := readGeoResults(path)
+	if err != nil {
+		return err
+	}
+
+	if geoFormat == "geojson" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(geo.ToGeoJSON(results))
+	}
+
+	if !geoWatch {
+		if len(results) == 0 {
+			fmt.Println("No geo data yet (is the station running with --geo and --stats-file?)")
+			return nil
+		}
+		printGeoResults(results)
+		return nil
+	}
+
+	if geoInterval < time.Second {
+		return fmt.Errorf("interval must be at least 1s")
+	}
+
+	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n", path)
+	prev := geo.NewSnapshot(results)
+	ticker := time.NewTicker(geoInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		results, err := readGeoResults(path)
+		if err != nil {
+			// The file is rewritten in place; try again next tick
+			continue
+		}
+		cur := geo.NewSnapshot(results)
+		if deltas := geo.Delta(prev, cur); len(deltas) > 0 {
+			printGeoDeltas(deltas)
+		}
+		prev = cur
+	}
+	return nil
+}
+
+func runGeoLookup(cmd *cobra.Command, args []string) error {
+	dataDir := GetDataDir()
+	var opts []geo.Option
+	asnPath := filepath.Join(dataDir, geo.ASNDatabaseFile)
+	hasASN := false
+	if _, err := os.Stat(asnPath); err == nil {
+		opts = append(opts, geo.WithASNDatabase(asnPath))
+		hasASN = true
+	}
+	collector := geo.NewCollector(filepath.Join(dataDir, geo.CountryDatabaseFile), opts...)
+	if err := collector.Open(); err != nil {
+		return err
+	}
+	defer collector.Stop()
+
+	if len(args) > 1 {
+		results, err := collector.LookupASNs(args)
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "CODE\tCOUNTRY\tASN\tORG\tIPS")
+		for _, r := range results {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\n", r.Code, r.Country, formatASN(r.ASN), r.Org, r.Count)
+		}
+		writer.Flush()
+		if skipped := len(args) - countIPs(results); skipped > 0 {
+			fmt.Printf("%d private/reserved addresses skipped; they are not counted in geo stats.\n", skipped)
+		}
+		return nil
+	}
+
+	loc, err := collector.Lookup(args[0])
+	if err != nil {
+		return err
+	}
+
+	country := "unknown"
+	if loc.Code != "" {
+		country = fmt.Sprintf("%s (%s)", loc.Country, loc.Code)
+	}
+	asn, org := formatASN(loc.ASN), loc.Org
+	if !hasASN {
+		asn = "unknown (no " + geo.ASNDatabaseFile + " in the data directory)"
+	}
+	if loc.Private {
+		country, asn, org = "n/a", "n/a", ""
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "IP:\t%s\n", loc.IP)
+	fmt.Fprintf(writer, "Private:\t%t\n", loc.Private)
+	fmt.Fprintf(writer, "Country:\t%s\n", country)
+	fmt.Fprintf(writer, "ASN:\t%s\n", asn)
+	if org != "" {
+		fmt.Fprintf(writer, "Org:\t%s\n", org)
+	}
+	writer.Flush()
+
+	if loc.Private {
+		fmt.Println("Private/reserved addresses are not counted in geo stats.")
+	}
+	return nil
+}
+
+// formatASN formats an autonomous system number as "AS13335", or "unknown" for 0
+func formatASN(asn uint) string {
+	if asn == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("AS%d", asn)
+}
+
+// countIPs returns the number of IPs in LookupASNs results
+func countIPs(results []geo.Result) int {
+	n := 0
+	for _, r := range results {
+		n += r.Count
+	}
+	return n
+}
+
+func printGeoResults(results []geo.Result) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CODE\tCOUNTRY\tLIVE\tTOTAL")
+	for _, r := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\n", r.Code, r.Country, r.Count, r.CountTotal)
+	}
+	writer.Flush()
+}
+
+func printGeoDeltas(deltas []geo.Result) {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	for _, d := range deltas {
+		fmt.Printf("%s [GEO] %s (%s): live %+d, total %+d\n", now, d.Code, d.Country, d.Count, d.CountTotal)
+	}
+}
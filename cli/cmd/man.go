@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var manCmd = &cobra.Command{
+	Use:   "man [dir]",
+	Short: "Generate man pages for conduit",
+	Long: `Generate a man page for conduit and each of its subcommands.
+
+Pages are written to the given directory (default: ./man), e.g.:
+
+  conduit man /usr/local/share/man/man1`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMan,
+}
+
+func init() {
+	rootCmd.AddCommand(manCmd)
+}
+
+func runMan(cmd *cobra.Command, args []string) error {
+	dir := "./man"
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create man directory: %w", err)
+	}
+
+	header := &doc.GenManHeader{
+		Title:   "CONDUIT",
+		Section: "1",
+		Source:  "Conduit " + version,
+		Manual:  "Conduit Manual",
+	}
+	if err := doc.GenManTree(rootCmd, header, dir); err != nil {
+		return fmt.Errorf("failed to generate man pages: %w", err)
+	}
+
+	fmt.Printf("Man pages written to %s\n", dir)
+	return nil
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Interactively configure and start Conduit",
+	Long: `Walk through the main settings for a Conduit station, validate them,
+and print the equivalent 'conduit start' command. Optionally start the
+station right away.
+
+Must be run from an interactive terminal; for scripts use 'conduit start'
+with flags instead.`,
+	RunE: runSetup,
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+}
+
+func runSetup(cmd *cobra.Command, args []string) error {
+	if !isTerminal(os.Stdin) {
+		return errors.New("setup requires an interactive terminal; use 'conduit start --max-clients N --bandwidth N' instead")
+	}
+
+	fmt.Printf("Conduit setup (%s/%s)\n", runtime.GOOS, runtime.GOARCH)
+	if os.Geteuid() == 0 {
+		fmt.Println("Note: running as root. Conduit does not need elevated privileges.")
+	}
+	fmt.Printf("Keys and state will be stored in %s\n\n", GetDataDir())
+
+	reader := bufio.NewReader(os.Stdin)
+
+	configPath := ""
+	if !config.HasEmbeddedConfig() {
+		for {
+			answer, err := prompt(reader, "Path to Psiphon network config (JSON)", "")
+			if err != nil {
+				return err
+			}
+			if err := config.ValidatePsiphonConfigPath(answer); err != nil {
+				fmt.Printf("  %v\n", err)
+				continue
+			}
+			configPath = answer
+			break
+		}
+	}
+
+	clients, err := promptInt(reader, fmt.Sprintf("Maximum clients (1-%d)", config.MaxClientsLimit),
+		config.DefaultMaxClients, 1, config.MaxClientsLimit)
+	if err != nil {
+		return err
+	}
+
+	bandwidth, err := promptBandwidth(reader)
+	if err != nil {
+		return err
+	}
+
+	geo, err := promptYesNo(reader, "Track client countries (downloads a GeoLite2 database)", false)
+	if err != nil {
+		return err
+	}
+
+	startArgs := []string{"conduit", "start",
+		"--max-clients", strconv.Itoa(clients),
+		"--bandwidth", strconv.FormatFloat(bandwidth, 'f', -1, 64)}
+	if configPath != "" {
+		startArgs = append(startArgs, "--psiphon-config", configPath)
+	}
+	if geo {
+		startArgs = append(startArgs, "--geo")
+	}
+	if dataDir != "./data" {
+		startArgs = append(startArgs, "--data-dir", dataDir)
+	}
+
+	fmt.Println("\nTo start Conduit with these settings later, run:")
+	fmt.Printf("  %s\n\n", shellJoin(startArgs))
+
+	startNow, err := promptYesNo(reader, "Start Conduit now", true)
+	if err != nil {
+		return err
+	}
+	if !startNow {
+		return nil
+	}
+
+	// Run through the start command so flag precedence and validation match
+	settings := [][2]string{
+		{"max-clients", strconv.Itoa(clients)},
+		{"bandwidth", strconv.FormatFloat(bandwidth, 'f', -1, 64)},
+	}
+	if configPath != "" {
+		settings = append(settings, [2]string{"psiphon-config", configPath})
+	}
+	if geo {
+		settings = append(settings, [2]string{"geo", "true"})
+	}
+	for _, setting := range settings {
+		if err := startCmd.Flags().Set(setting[0], setting[1]); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", setting[0], setting[1], err)
+		}
+	}
+
+	return runStart(startCmd, nil)
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompt asks a question and returns the trimmed answer, or def if empty
+func prompt(reader *bufio.Reader, question, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// promptInt asks for an integer in [min, max], repeating until valid
+func promptInt(reader *bufio.Reader, question string, def, min, max int) (int, error) {
+	for {
+		answer, err := prompt(reader, question, strconv.Itoa(def))
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < min || n > max {
+			fmt.Printf("  Please enter a whole number between %d and %d\n", min, max)
+			continue
+		}
+		return n, nil
+	}
+}
+
+// promptBandwidth asks for a bandwidth limit in Mbps (-1 for unlimited)
+func promptBandwidth(reader *bufio.Reader) (float64, error) {
+	def := strconv.FormatFloat(config.DefaultBandwidthMbps, 'f', -1, 64)
+	for {
+		answer, err := prompt(reader, "Bandwidth limit per peer in Mbps (-1 for unlimited)", def)
+		if err != nil {
+			return 0, err
+		}
+		v, err := strconv.ParseFloat(answer, 64)
+		if err != nil || (v != config.UnlimitedBandwidth && (v < 1 || v > config.MaxBandwidthMbps)) {
+			fmt.Printf("  Please enter a number from 1 to %g, or -1 for unlimited\n", config.MaxBandwidthMbps)
+			continue
+		}
+		return v, nil
+	}
+}
+
+// promptYesNo asks a yes/no question
+func promptYesNo(reader *bufio.Reader, question string, def bool) (bool, error) {
+	defStr := "y/N"
+	if def {
+		defStr = "Y/n"
+	}
+	for {
+		fmt.Printf("%s? (%s) ", question, defStr)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("failed to read answer: %w", err)
+		}
+		switch strings.TrimSpace(strings.ToLower(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Println("  Please answer y or n")
+	}
+}
+
+// shellJoin joins args into a command line that can be pasted into a shell,
+// quoting any argument with spaces or shell metacharacters. Windows gets
+// double quotes, since cmd.exe doesn't understand single quotes.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes arg for the current platform's shell if it needs it
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./:=,+@%", r))
+	}) < 0 {
+		return arg
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
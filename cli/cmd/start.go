@@ -21,17 +21,25 @@ package cmd
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Psiphon-Inc/conduit/cli/internal/conduit"
 	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+	"github.com/Psiphon-Inc/conduit/cli/internal/logging"
+	"github.com/Psiphon-Inc/conduit/cli/internal/statsink"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -40,8 +48,32 @@ var (
 	psiphonConfigPath string
 	statsFilePath     string
 	geoEnabled        bool
+	geoEstimateUnique bool
+	geoCity           bool
+	geoASN            bool
+	geoWindow         time.Duration
+	geoPersist        bool
+	geoRateWindow     time.Duration
+	geoObservations   string
 	metricsAddr       string
 	idleRestart       string
+	logRateLimit      int
+	logTag            string
+	logStatsJSON      bool
+	logSyslog         bool
+	syslogFacility    string
+	syslogTag         string
+	statsSink         string
+	safeMode          bool
+	autoTune          bool
+	ephemeral         bool
+	networkWatch      bool
+	reportWebhook     string
+	reportSecret      string
+	reportInterval    time.Duration
+	notifyURL         string
+	configFile        string
+	pidFilePath       string
 )
 
 var startCmd = &cobra.Command{
@@ -65,31 +97,166 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 
 	startCmd.Flags().IntVarP(&maxClients, "max-clients", "m", config.DefaultMaxClients, "maximum number of proxy clients (1-1000)")
-	startCmd.Flags().Float64VarP(&bandwidthMbps, "bandwidth", "b", config.DefaultBandwidthMbps, "total bandwidth limit in Mbps (-1 for unlimited)")
+	startCmd.Flags().Float64VarP(&bandwidthMbps, "bandwidth", "b", config.DefaultBandwidthMbps, "bandwidth limit per peer in Mbps (1-40, -1 for unlimited)")
+	startCmd.Flags().BoolVar(&autoTune, "auto-tune", false, "when max-clients isn't set, derive it from CPU count and RAM instead of using 50")
 	startCmd.Flags().StringVarP(&statsFilePath, "stats-file", "s", "", "persist stats to JSON file (default: stats.json in data dir if flag used without value)")
 	startCmd.Flags().Lookup("stats-file").NoOptDefVal = "stats.json"
-	startCmd.Flags().BoolVar(&geoEnabled, "geo", false, "enable client location tracking (requires tcpdump, geoip-bin)")
+	startCmd.Flags().BoolVar(&geoEnabled, "geo", false, "enable client location tracking (downloads the GeoLite2-Country database to the data dir on first use)")
+	startCmd.Flags().BoolVar(&geoCity, "geo-city", false, "also track clients by region and city (downloads the ~60MB GeoLite2-City database; more identifying than country)")
+	startCmd.Flags().BoolVar(&geoASN, "geo-asn", false, "also track clients by network/ISP (downloads the GeoLite2-ASN database)")
+	startCmd.Flags().DurationVar(&geoWindow, "geo-window", 0, "count unique clients per country over this sliding window, e.g. 1h (default: since start)")
+	startCmd.Flags().BoolVar(&geoPersist, "geo-persist", false, "keep per-country totals across restarts (saved to geo_results.json in the data dir)")
+	startCmd.Flags().DurationVar(&geoRateWindow, "geo-rate-window", geo.DefaultRateWindow, "period the new-client rate (connectionRate) is averaged over")
+	startCmd.Flags().StringVar(&geoObservations, "geo-observations", "", "append each client connection as a JSON line (hashed IP, country) to this file, or - for stdout with --log-syslog")
+	startCmd.Flags().BoolVar(&geoEstimateUnique, "geo-estimate-unique", false, "estimate unique clients with HyperLogLog (bounded memory, ~1% error)")
 	startCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "address for Prometheus metrics endpoint (e.g., :9090 or 127.0.0.1:9090)")
+	startCmd.Flags().StringVar(&statsSink, "stats-sink", "", "push stats to statsd/InfluxDB (e.g., udp://127.0.0.1:8125?format=statsd|influx&interval=10s)")
 	startCmd.Flags().StringVarP(&psiphonConfigPath, "psiphon-config", "c", "", "path to Psiphon network config file (JSON)")
+	startCmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "use a fresh in-memory identity for this run; keys are never written to disk")
+	startCmd.Flags().StringVar(&reportWebhook, "report-webhook", "", "POST a JSON activity summary to this URL once per report interval")
+	startCmd.Flags().StringVar(&reportSecret, "report-secret", "", "sign report requests with HMAC-SHA256 using this key (X-Conduit-Signature header)")
+	startCmd.Flags().StringVar(&notifyURL, "notify-url", "", "POST a JSON event to this URL when the service starts, connects, disconnects or stops (Slack/Discord webhooks work)")
+	startCmd.Flags().DurationVar(&reportInterval, "report-interval", 24*time.Hour, "how often to send the report webhook")
+	startCmd.Flags().BoolVar(&networkWatch, "reconnect-on-network-change", false, "reconnect in-process when the host's network changes (laptops, mobile hosts)")
+	startCmd.Flags().BoolVar(&safeMode, "safe-mode", false, "run only the core relay: disable geo, metrics, stats sink, webhooks, syslog, JSON stats, network watching and verbose logging for this run")
+	startCmd.Flags().StringVar(&logTag, "log-tag", "", "prefix every log line with [TAG], to tell stations apart in a shared log")
+	startCmd.Flags().BoolVar(&logStatsJSON, "log-stats-json", false, "also log each stats update as a [STATS-JSON] line with numeric fields, for log pipelines")
+	startCmd.Flags().BoolVar(&logSyslog, "log-syslog", false, "send logs to syslog (the Event Log on Windows) instead of stdout")
+	startCmd.Flags().StringVar(&syslogFacility, "syslog-facility", "daemon", "syslog facility for --log-syslog: daemon, user or local0-local7")
+	startCmd.Flags().StringVar(&syslogTag, "syslog-tag", "conduit", "syslog program name (Event Log source on Windows) for --log-syslog")
+	startCmd.Flags().IntVar(&logRateLimit, "log-rate-limit", config.DefaultLogRateLimit, "maximum log lines per second, repeated lines are coalesced (0 for unlimited)")
 	startCmd.Flags().StringVar(&idleRestart, "idle-restart", "", "restart service after idle duration (e.g., 30m, 1h, 2h)")
+	startCmd.Flags().StringVar(&pidFilePath, "pid-file", "", "write the process id to this file while running (refuses to start if it names a running process)")
+	startCmd.Flags().StringVar(&configFile, "config", "", "YAML settings file keyed by flag name (default: conduit.yaml in the data dir, then /etc/conduit)")
+
+	// 'config show' resolves the same flags
+	configShowCmd.Flags().AddFlagSet(startCmd.Flags())
 }
 
-func runStart(cmd *cobra.Command, args []string) error {
+// applyEnv sets flags that weren't given on the command line from their
+// CONDUIT_* environment variables, e.g. CONDUIT_MAX_CLIENTS for --max-clients
+func applyEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		name := config.EnvName(flag.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", name, setErr)
+			return
+		}
+		settingSources[flag.Name] = name
+	})
+	return err
+}
+
+// settingSources records where flags set by applyEnv and applyConfigFile
+// came from, for error messages
+var settingSources = make(map[string]string)
+
+// settingSource names where a start flag's value came from, e.g.
+// CONDUIT_BANDWIDTH or "bandwidth in /etc/conduit/conduit.yaml"
+func settingSource(name string) string {
+	if source, ok := settingSources[name]; ok {
+		return source
+	}
+	return "--" + name
+}
+
+// applyConfigFile sets flags from the settings file that weren't given on
+// the command line or in the environment, so both override the file.
+// Returns the file used, or "" if there is none.
+func applyConfigFile(cmd *cobra.Command) (string, error) {
+	path := configFile
+	if path == "" {
+		if path = config.FindConfigFile(GetDataDir()); path == "" {
+			return "", nil
+		}
+	}
+
+	values, err := config.ReadConfigFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || name == "config" || name == "help" {
+			return "", fmt.Errorf("%s: unknown setting %q (settings are start flag names, e.g. max-clients)", path, name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, values[name]); err != nil {
+			return "", fmt.Errorf("%s: invalid %s: %w", path, name, err)
+		}
+		settingSources[name] = fmt.Sprintf("%s in %s", name, path)
+	}
+
+	return path, nil
+}
+
+// reloadLimits re-reads max-clients and bandwidth from the settings file,
+// keeping values pinned by a flag or environment variable. Clamped values
+// are reported to logf. Returns nil if the limits are unchanged.
+func reloadLimits(cfg *config.Config, path string, pinned map[string]bool, logf func(string, ...any)) (*config.Config, error) {
+	values, err := config.ReadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	maxClients := cfg.MaxClients
+	if value, ok := values["max-clients"]; ok && !pinned["max-clients"] {
+		if maxClients, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%s: invalid max-clients %q", path, value)
+		}
+	}
+	bandwidth := cfg.BandwidthMbps()
+	if value, ok := values["bandwidth"]; ok && !pinned["bandwidth"] {
+		if bandwidth, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("%s: invalid bandwidth %q", path, value)
+		}
+	}
+
+	next, notes := cfg.WithLimits(maxClients, bandwidth)
+	for _, note := range notes {
+		logf("[WARN] %s\n", note)
+	}
+	if next.MaxClients == cfg.MaxClients && next.BandwidthBytesPerSecond == cfg.BandwidthBytesPerSecond {
+		return nil, nil
+	}
+	return next, nil
+}
+
+// startOptions validates the start flags and builds the options for
+// config.LoadOrCreate
+func startOptions(cmd *cobra.Command) (config.Options, error) {
 	// Determine psiphon config source: flag > embedded > error
 	effectiveConfigPath := psiphonConfigPath
 	useEmbedded := false
 
 	if psiphonConfigPath != "" {
-		// User provided a config path - validate it exists
-		if _, err := os.Stat(psiphonConfigPath); os.IsNotExist(err) {
-			return fmt.Errorf("psiphon config file not found: %s", psiphonConfigPath)
+		// User provided a config path - validate it is a readable file
+		if err := config.ValidatePsiphonConfigPath(psiphonConfigPath); err != nil {
+			return config.Options{}, err
 		}
 	} else if config.HasEmbeddedConfig() {
 		// No flag provided, but we have embedded config
 		useEmbedded = true
 	} else {
 		// No flag and no embedded config
-		return fmt.Errorf("psiphon config required: use --psiphon-config flag or build with embedded config")
+		return config.Options{}, fmt.Errorf("psiphon config required: use --psiphon-config flag or build with embedded config")
 	}
 
 	// Resolve stats file path - if relative, place in data dir
@@ -100,8 +267,8 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	maxClientsFromFlag := 0
 	if cmd.Flags().Changed("max-clients") {
-		if maxClients < 1 {
-			return fmt.Errorf("max-clients must be between 1 and %d", config.MaxClientsLimit)
+		if maxClients < 1 || maxClients > config.MaxClientsLimit {
+			return config.Options{}, fmt.Errorf("%s is %d, must be between 1 and %d", settingSource("max-clients"), maxClients, config.MaxClientsLimit)
 		}
 		maxClientsFromFlag = maxClients
 	}
@@ -109,8 +276,9 @@ func runStart(cmd *cobra.Command, args []string) error {
 	bandwidthFromFlag := 0.0
 	bandwidthFromFlagSet := false
 	if cmd.Flags().Changed("bandwidth") {
-		if bandwidthMbps != config.UnlimitedBandwidth && bandwidthMbps < 1 {
-			return fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+		if bandwidthMbps != config.UnlimitedBandwidth && (bandwidthMbps < 1 || bandwidthMbps > config.MaxBandwidthMbps) {
+			return config.Options{}, fmt.Errorf("%s is %g Mbps, must be between 1 and %g Mbps per peer (or -1 for unlimited)",
+				settingSource("bandwidth"), bandwidthMbps, config.MaxBandwidthMbps)
 		}
 		bandwidthFromFlag = bandwidthMbps
 		bandwidthFromFlagSet = true
@@ -121,16 +289,84 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if idleRestart != "" {
 		d, err := time.ParseDuration(idleRestart)
 		if err != nil {
-			return fmt.Errorf("invalid idle-restart duration %q: %w (use format like 30m, 1h, 2h)", idleRestart, err)
+			return config.Options{}, fmt.Errorf("invalid idle-restart duration %q: %w (use format like 30m, 1h, 2h)", idleRestart, err)
 		}
 		if d < 30*time.Minute {
-			return fmt.Errorf("idle-restart must be at least 30m")
+			return config.Options{}, fmt.Errorf("idle-restart must be at least 30m")
 		}
 		idleRestartDuration = d
 	}
 
-	// Load or create configuration (auto-generates keys on first run)
-	cfg, err := config.LoadOrCreate(config.Options{
+	if statsSink != "" {
+		if _, err := statsink.ParseURL(statsSink); err != nil {
+			return config.Options{}, err
+		}
+	}
+
+	if logRateLimit < 0 {
+		return config.Options{}, fmt.Errorf("log-rate-limit must be 0 (unlimited) or greater")
+	}
+
+	if strings.ContainsAny(logTag, " \t[]") {
+		return config.Options{}, fmt.Errorf("log-tag must not contain spaces or brackets")
+	}
+
+	if reportWebhook != "" {
+		u, err := url.Parse(reportWebhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return config.Options{}, fmt.Errorf("report-webhook must be an http(s) URL")
+		}
+		if reportInterval < time.Minute {
+			return config.Options{}, fmt.Errorf("report-interval must be at least 1m")
+		}
+	}
+
+	if notifyURL != "" {
+		u, err := url.Parse(notifyURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return config.Options{}, fmt.Errorf("notify-url must be an http(s) URL")
+		}
+	}
+
+	if geoCity && !geoEnabled {
+		return config.Options{}, fmt.Errorf("--geo-city requires --geo")
+	}
+	if geoASN && !geoEnabled {
+		return config.Options{}, fmt.Errorf("--geo-asn requires --geo")
+	}
+	if geoWindow != 0 {
+		if !geoEnabled {
+			return config.Options{}, fmt.Errorf("--geo-window requires --geo")
+		}
+		if geoEstimateUnique {
+			return config.Options{}, fmt.Errorf("--geo-window can't be combined with --geo-estimate-unique")
+		}
+		if geoWindow < time.Minute {
+			return config.Options{}, fmt.Errorf("geo-window must be at least 1m")
+		}
+	}
+	if geoPersist {
+		if !geoEnabled {
+			return config.Options{}, fmt.Errorf("--geo-persist requires --geo")
+		}
+		if geoWindow != 0 {
+			return config.Options{}, fmt.Errorf("--geo-persist can't be combined with --geo-window")
+		}
+	}
+	if cmd.Flags().Changed("geo-rate-window") && !geoEnabled {
+		return config.Options{}, fmt.Errorf("--geo-rate-window requires --geo")
+	}
+	if geoRateWindow < 10*time.Second {
+		return config.Options{}, fmt.Errorf("%s is %s, must be at least 10s", settingSource("geo-rate-window"), geoRateWindow)
+	}
+	if geoObservations != "" && !geoEnabled {
+		return config.Options{}, fmt.Errorf("--geo-observations requires --geo")
+	}
+	if geoObservations == "-" && !logSyslog {
+		return config.Options{}, fmt.Errorf("%s is -, which needs --log-syslog so logs don't share stdout; give a file path instead", settingSource("geo-observations"))
+	}
+
+	return config.Options{
 		DataDir:           GetDataDir(),
 		PsiphonConfigPath: effectiveConfigPath,
 		UseEmbeddedConfig: useEmbedded,
@@ -140,12 +376,92 @@ func runStart(cmd *cobra.Command, args []string) error {
 		Verbosity:         Verbosity(),
 		StatsFile:         resolvedStatsFile,
 		GeoEnabled:        geoEnabled,
+		GeoEstimateUnique: geoEstimateUnique,
+		GeoCity:           geoCity,
+		GeoASN:            geoASN,
+		GeoWindow:         geoWindow,
+		GeoPersist:        geoPersist,
+		GeoRateWindow:     geoRateWindow,
+		GeoObservations:   geoObservations,
 		MetricsAddr:       metricsAddr,
 		IdleRestart:       idleRestartDuration,
-	})
+		LogRateLimit:      logRateLimit,
+		LogTag:            logTag,
+		LogStatsJSON:      logStatsJSON,
+		LogSyslog:         logSyslog,
+		SyslogFacility:    syslogFacility,
+		SyslogTag:         syslogTag,
+		StatsSink:         statsSink,
+		SafeMode:          safeMode,
+		Ephemeral:         ephemeral,
+		AutoTune:          autoTune,
+		NetworkWatch:      networkWatch,
+		ReportWebhook:     reportWebhook,
+		ReportSecret:      reportSecret,
+		ReportInterval:    reportInterval,
+		NotifyURL:         notifyURL,
+	}, nil
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	if err := applyEnv(cmd); err != nil {
+		return err
+	}
+	// Flags set on the command line or in the environment win over the
+	// config file, including when it is reloaded
+	pinned := make(map[string]bool)
+	cmd.Flags().Visit(func(flag *pflag.Flag) { pinned[flag.Name] = true })
+	settingsPath, err := applyConfigFile(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts, err := startOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	// With the system log, stdout carries only data (--geo-observations -),
+	// so startup messages from before the service logger exists go to stderr
+	if opts.LogSyslog {
+		logging.SetOutput(os.Stderr)
+	}
+
+	// Load or create configuration (auto-generates keys on first run)
+	cfg, err := config.LoadOrCreate(opts)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	defer cfg.Close()
+
+	if pidFilePath != "" {
+		pidFile, err := config.CreatePIDFile(pidFilePath)
+		if err != nil {
+			return err
+		}
+		defer pidFile.Remove()
+	}
+
+	// Create conduit service. Its logger writes to stdout, the journal or
+	// the system log, so the messages below use it too.
+	service, err := conduit.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create conduit service: %w", err)
+	}
+	logf := service.Log().Printf
+
+	if settingsPath != "" {
+		logf("Using settings from %s\n", settingsPath)
+	}
+	if cfg.AutoTuned != "" {
+		logf("Auto-tuned %s\n", cfg.AutoTuned)
+	}
+	if cfg.Ephemeral {
+		logf("[EPHEMERAL] Using a temporary identity; it will be discarded on exit and broker reputation will not carry over\n")
+	}
+	if cfg.SafeMode {
+		logf("[SAFE MODE] Geo, metrics, stats sink, webhooks, syslog, JSON stats, network watching and verbose logging are disabled for this run\n")
+	}
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -157,41 +473,72 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	go func() {
 		<-sigChan
-		fmt.Println("\nShutting down...")
+		logf("\nShutting down...\n")
 		cancel()
 	}()
 
-	// Run the service (with restart loop if idle-restart is enabled)
-	for {
-		// Create conduit service
-		service, err := conduit.New(cfg)
-		if err != nil {
-			return fmt.Errorf("failed to create conduit service: %w", err)
-		}
-
-		// Run the service
-		err = service.Run(ctx)
-
-		// Check if we should restart due to idle timeout
-		if errors.Is(err, conduit.ErrIdleRestart) {
-			// Brief pause before restarting
-			select {
-			case <-ctx.Done():
-				fmt.Println("Stopped.")
-				return nil
-			case <-time.After(5 * time.Second):
-				// Continue to restart
+	// SIGHUP reloads the GeoIP database and re-reads the limits from the
+	// config file. Tunnel-core can't change limits on a running proxy, so
+	// new limits restart the relay in-process, which disconnects clients.
+	active := cfg
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			if active.GeoEnabled {
+				if err := service.ReloadGeoDB(); err != nil {
+					logf("[ERROR] Reload failed: %v\n", err)
+				}
 			}
-			continue
+			if settingsPath == "" {
+				logf("[WARN] No settings file to reload; start with --config or add %s to the data directory\n", config.ConfigFileName)
+				continue
+			}
+			next, err := reloadLimits(active, settingsPath, pinned, logf)
+			if err != nil {
+				logf("[ERROR] Config reload failed, keeping current settings: %v\n", err)
+				continue
+			}
+			if next == nil {
+				logf("[OK] Reloaded config: limits unchanged\n")
+				continue
+			}
+			bandwidth := "unlimited"
+			if mbps := next.BandwidthMbps(); mbps != config.UnlimitedBandwidth {
+				bandwidth = fmt.Sprintf("%g Mbps per peer", mbps)
+			}
+			logf("[OK] Reloaded config: max clients %d, bandwidth %s\n", next.MaxClients, bandwidth)
+			active = next
+			service.SetLimits(next.MaxClients, next.BandwidthBytesPerSecond)
 		}
+	}()
 
-		// Any other error or normal shutdown
-		if err != nil && ctx.Err() == nil {
-			return fmt.Errorf("conduit service error: %w", err)
-		}
-		break
+	// Run the service until shutdown
+	if err := runService(ctx, service); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("conduit service error: %w", err)
 	}
 
-	fmt.Println("Stopped.")
+	logf("Stopped.\n")
 	return nil
 }
+
+// runService runs the service, turning a panic into an error after writing
+// a crash report to the data directory
+func runService(ctx context.Context, service *conduit.Service) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		stack := debug.Stack()
+		fmt.Fprintf(os.Stderr, "[ERROR] Conduit crashed: %v\n%s", r, stack)
+		if path, werr := service.WriteCrashReport(r, stack, version); werr != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] %v\n", werr)
+		} else {
+			fmt.Fprintf(os.Stderr, "Crash report written to %s\n", path)
+		}
+		err = fmt.Errorf("service panicked: %v", r)
+	}()
+
+	return service.Run(ctx)
+}
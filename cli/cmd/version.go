@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"text/tabwriter"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at build time with -ldflags -X (see Makefile)
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+var versionOutput string
+
+// VersionInfo describes the running binary
+type VersionInfo struct {
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	BuildDate      string `json:"buildDate"`
+	GoVersion      string `json:"goVersion"`
+	Platform       string `json:"platform"`
+	EmbeddedConfig bool   `json:"embeddedConfig"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: `Show the version, git commit, build date and Go version of this binary,
+and whether it has an embedded Psiphon config. Include this in bug reports.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", "text", "output format: text or json")
+}
+
+// getVersionInfo returns the build metadata, falling back to the VCS info
+// the Go toolchain embeds when the ldflags were not set
+func getVersionInfo() VersionInfo {
+	info := VersionInfo{
+		Version:        version,
+		Commit:         commit,
+		BuildDate:      buildDate,
+		GoVersion:      runtime.Version(),
+		Platform:       runtime.GOOS + "/" + runtime.GOARCH,
+		EmbeddedConfig: config.HasEmbeddedConfig(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+					if len(info.Commit) > 12 {
+						info.Commit = info.Commit[:12]
+					}
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := getVersionInfo()
+
+	switch versionOutput {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	case "text":
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "Version:\t%s\n", info.Version)
+		fmt.Fprintf(writer, "Commit:\t%s\n", info.Commit)
+		fmt.Fprintf(writer, "Built:\t%s\n", info.BuildDate)
+		fmt.Fprintf(writer, "Go:\t%s\n", info.GoVersion)
+		fmt.Fprintf(writer, "Platform:\t%s\n", info.Platform)
+		fmt.Fprintf(writer, "Embedded config:\t%t\n", info.EmbeddedConfig)
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q (use text or json)", versionOutput)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxRecentStats is the number of stats snapshots kept for crash reports
+const maxRecentStats = 10
+
+// CrashReport is the structured report written when the service panics
+type CrashReport struct {
+	Timestamp   string      `json:"timestamp"`
+	Version     string      `json:"version"`
+	Panic       string      `json:"panic"`
+	Stack       string      `json:"stack"`
+	RecentStats []StatsJSON `json:"recentStats,omitempty"`
+}
+
+// WriteCrashReport writes a crash report for a recovered panic to
+// crash-<timestamp>.json in the data directory and returns its path
+func (s *Service) WriteCrashReport(recovered any, stack []byte, version string) (string, error) {
+	now := time.Now()
+	report := CrashReport{
+		Timestamp: now.Format(time.RFC3339),
+		Version:   version,
+		Panic:     fmt.Sprint(recovered),
+		Stack:     string(stack),
+	}
+
+	// The panic may have happened with the lock held; don't deadlock on it
+	if s.mu.TryRLock() {
+		report.RecentStats = append([]StatsJSON(nil), s.recentStats...)
+		s.mu.RUnlock()
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal crash report: %w", err)
+	}
+
+	path := filepath.Join(s.config.DataDir, fmt.Sprintf("crash-%s.json", now.Format("20060102-150405")))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+
+	return path, nil
+}
@@ -26,28 +26,63 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Psiphon-Inc/conduit/cli/internal/config"
 	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+	"github.com/Psiphon-Inc/conduit/cli/internal/httpapi"
+	"github.com/Psiphon-Inc/conduit/cli/internal/logging"
 	"github.com/Psiphon-Inc/conduit/cli/internal/metrics"
+	"github.com/Psiphon-Inc/conduit/cli/internal/netwatch"
+	"github.com/Psiphon-Inc/conduit/cli/internal/report"
+	"github.com/Psiphon-Inc/conduit/cli/internal/statsink"
 	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon"
 	"github.com/Psiphon-Labs/psiphon-tunnel-core/psiphon/common/inproxy"
 )
 
-// ErrIdleRestart is returned when the service should restart due to idle timeout
-var ErrIdleRestart = errors.New("idle restart triggered")
+// geoCheckpointInterval is how often geo totals are saved with --geo-persist
+const geoCheckpointInterval = 5 * time.Minute
+
+// idleRestartPause is how long the service waits before replacing an idle
+// controller
+const idleRestartPause = 5 * time.Second
+
+// notifyCloseTimeout bounds how long a run waits to deliver its last
+// --notify-url events
+const notifyCloseTimeout = 10 * time.Second
+
+// brokerFailureLimit is how many failed announcements in a row, with no
+// answer from the broker in between, mean the broker connection is lost.
+// Tunnel-core logs only a sample of repeated errors, so this is small.
+const brokerFailureLimit = 2
+
+// errIdleRestart is returned when the controller should restart due to idle timeout
+var errIdleRestart = errors.New("idle restart triggered")
+
+// errLimitsChanged is returned when SetLimits stopped the controller
+var errLimitsChanged = errors.New("limits changed")
 
 // Service represents the Conduit inproxy service
 type Service struct {
-	config       *config.Config
-	controller   *psiphon.Controller
-	stats        *Stats
-	geoCollector *geo.Collector
-	metrics      *metrics.Metrics
-	mu           sync.RWMutex
+	config        *config.Config
+	stats         *Stats
+	geoCollector  *geo.Collector
+	metrics       *metrics.Metrics
+	log           *logging.Throttle
+	notifier      *report.Notifier   // state changes for --notify-url (nil = disabled)
+	recentStats   []StatsJSON        // last few stats snapshots, for crash reports
+	bytesUpBase   int64              // bytes moved by earlier controllers, as each
+	bytesDownBase int64              // controller counts its totals from zero
+	maxClients    int                // current limits, changed by SetLimits
+	bandwidth     int                // bytes per second per client (0 = unlimited)
+	stopRun       context.CancelFunc // stops the running controller (nil = none)
+	brokerFails   int                // failed announcements in a row, see brokerFailureLimit
+	notifiedLive  bool               // last connection state sent to --notify-url
+	mu            sync.RWMutex
 }
 
 // Stats tracks proxy activity statistics
@@ -61,59 +96,140 @@ type Stats struct {
 	IsLive            bool      // Connected to broker and ready to accept clients
 }
 
-// StatsJSON represents the JSON structure for persisted stats
+// StatsJSON represents the JSON structure for persisted stats, the /stats
+// endpoint and [STATS-JSON] log lines
 type StatsJSON struct {
-	ConnectingClients int          `json:"connectingClients"`
-	ConnectedClients  int          `json:"connectedClients"`
-	TotalBytesUp      int64        `json:"totalBytesUp"`
-	TotalBytesDown    int64        `json:"totalBytesDown"`
-	UptimeSeconds     int64        `json:"uptimeSeconds"`
-	IdleSeconds       int64        `json:"idleSeconds"`
-	IsLive            bool         `json:"isLive"`
-	Geo               []geo.Result `json:"geo,omitempty"`
-	Timestamp         string       `json:"timestamp"`
+	ConnectingClients int              `json:"connectingClients"`
+	ConnectedClients  int              `json:"connectedClients"`
+	TotalBytesUp      int64            `json:"totalBytesUp"`
+	TotalBytesDown    int64            `json:"totalBytesDown"`
+	UptimeSeconds     int64            `json:"uptimeSeconds"`
+	IdleSeconds       int64            `json:"idleSeconds"`
+	IsLive            bool             `json:"isLive"`
+	Ephemeral         bool             `json:"ephemeral,omitempty"`      // Identity is discarded on exit
+	UniqueClients     uint64           `json:"uniqueClients,omitempty"`  // Estimated distinct client IPs (geo only)
+	ConnectionRate    float64          `json:"connectionRate,omitempty"` // New client IPs per second over the rate window (geo only)
+	Geo               []geo.Result     `json:"geo,omitempty"`
+	GeoCities         []geo.CityResult `json:"geoCities,omitempty"` // Only with --geo-city
+	GeoASNs           []geo.ASNResult  `json:"geoAsns,omitempty"`   // Only with --geo-asn
+	Timestamp         string           `json:"timestamp"`
 }
 
 // New creates a new Conduit service
 func New(cfg *config.Config) (*Service, error) {
 	s := &Service{
-		config: cfg,
+		config:     cfg,
+		log:        logging.NewThrottle(os.Stdout, cfg.LogRateLimit),
+		maxClients: cfg.MaxClients,
+		bandwidth:  cfg.BandwidthBytesPerSecond,
 		stats: &Stats{
 			StartTime: time.Now(),
 		},
 	}
+	if cfg.LogTag != "" {
+		s.log.SetTag(cfg.LogTag)
+	}
+	if cfg.LogSyslog {
+		if err := s.log.UseSysLog(cfg.SyslogFacility, cfg.SyslogTag); err != nil {
+			return nil, err
+		}
+	}
 
 	if cfg.MetricsAddr != "" {
-		s.metrics = metrics.New(metrics.GaugeFuncs{
+		gaugeFuncs := metrics.GaugeFuncs{
 			GetUptimeSeconds: s.getUptimeSeconds,
 			GetIdleSeconds:   s.getIdleSecondsFloat,
-		})
+		}
+		if cfg.GeoEnabled {
+			gaugeFuncs.GetUniqueClients = s.getUniqueClients
+			gaugeFuncs.GetClientsByCountry = s.getClientsByCountry
+			gaugeFuncs.GetConnectionRate = s.getConnectionRate
+		}
+		s.metrics = metrics.New(gaugeFuncs)
 		s.metrics.SetConfig(cfg.MaxClients, cfg.BandwidthBytesPerSecond)
 	}
 
 	return s, nil
 }
 
-// Run starts the Conduit inproxy service and blocks until context is cancelled
-// Returns ErrIdleRestart if the service should be restarted due to idle timeout
+// Run starts the Conduit inproxy service and blocks until context is
+// cancelled. With idle restart, an idle controller is replaced in-process.
 func (s *Service) Run(ctx context.Context) error {
+	// Report pending repeat counts and close the system log at exit
+	defer s.log.Close()
+
 	if s.config.GeoEnabled {
-		dbPath := s.config.DataDir + "/GeoLite2-Country.mmdb"
-		s.geoCollector = geo.NewCollector(dbPath)
-		if err := s.geoCollector.Start(ctx); err != nil {
-			fmt.Printf("[WARN] Geo disabled: %v\n", err)
-			s.geoCollector = nil
+		dbPath := filepath.Join(s.config.DataDir, geo.CountryDatabaseFile)
+		var opts []geo.Option
+		if s.config.GeoEstimateUnique {
+			opts = append(opts, geo.WithUniqueEstimation())
+		}
+		if s.config.GeoWindow > 0 {
+			opts = append(opts, geo.WithWindow(s.config.GeoWindow))
+		}
+		if s.config.GeoRateWindow > 0 {
+			opts = append(opts, geo.WithRateWindow(s.config.GeoRateWindow))
+		}
+		if s.config.GeoPersist {
+			opts = append(opts, geo.WithCheckpoint(filepath.Join(s.config.DataDir, geo.ResultsFile), geoCheckpointInterval))
+		}
+		if s.config.GeoCity {
+			opts = append(opts, geo.WithCityDatabase(filepath.Join(s.config.DataDir, geo.CityDatabaseFile)))
+		}
+		if s.config.GeoASN {
+			opts = append(opts, geo.WithASNDatabase(filepath.Join(s.config.DataDir, geo.ASNDatabaseFile)))
+		}
+		// Deferred after the file's Close, so queued lines are written first
+		var observations *geo.ObservationLog
+		switch s.config.GeoObservations {
+		case "":
+		case "-":
+			observations = geo.NewObservationLog(os.Stdout)
+		default:
+			f, err := os.OpenFile(s.config.GeoObservations, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to open geo observations file: %w", err)
+			}
+			defer f.Close()
+			observations = geo.NewObservationLog(f)
+		}
+		if observations != nil {
+			defer observations.Close()
+			opts = append(opts, geo.WithObservationLog(observations))
+		}
+		opts = append(opts, geo.WithLogger(s.log.Printf))
+		collector := geo.NewCollector(dbPath, opts...)
+		if err := collector.Start(ctx); err != nil {
+			s.log.Printf("[WARN] Geo disabled: %v\n", err)
 		} else {
-			fmt.Println("[GEO] Tracking enabled")
+			s.mu.Lock()
+			s.geoCollector = collector
+			s.mu.Unlock()
+			defer collector.Stop()
+			s.log.Printf("[GEO] Tracking enabled\n")
+			if s.config.Verbosity >= 1 {
+				go s.logGeoEvents(collector.Subscribe())
+			}
 		}
 	}
 
 	if s.metrics != nil && s.config.MetricsAddr != "" {
+		api := &httpapi.API{
+			Stats: func() any { return s.StatsSnapshot() },
+			Geo: func() *geo.Collector {
+				s.mu.RLock()
+				defer s.mu.RUnlock()
+				return s.geoCollector
+			},
+			Ready: func() bool { return s.GetStats().IsLive },
+		}
+		api.Register(s.metrics)
+
 		if err := s.metrics.StartServer(s.config.MetricsAddr); err != nil {
 			return fmt.Errorf("failed to start metrics server: %w", err)
 		}
 
-		fmt.Printf("Prometheus metrics available at http://%s/metrics\n", s.config.MetricsAddr)
+		s.log.Printf("Prometheus metrics available at http://%s/metrics (JSON: /stats, /geo, /ready)\n", s.config.MetricsAddr)
 
 		// Ensure metrics server is shut down when we're done
 		defer func() {
@@ -121,11 +237,35 @@ func (s *Service) Run(ctx context.Context) error {
 			defer cancel()
 
 			if err := s.metrics.Shutdown(ctx); err != nil {
-				fmt.Printf("[ERROR] Failed to shutdown metrics server: %v\n", err)
+				s.log.Printf("[ERROR] Failed to shutdown metrics server: %v\n", err)
 			}
 		}()
 	}
 
+	if s.config.StatsSink != "" {
+		sinkConfig, err := statsink.ParseURL(s.config.StatsSink)
+		if err != nil {
+			return err
+		}
+		sink, err := statsink.Open(sinkConfig)
+		if err != nil {
+			return err
+		}
+		defer sink.Close()
+		go s.pushStats(ctx, sink, sinkConfig.Interval)
+		s.log.Printf("Pushing %s stats to %s every %s\n", sinkConfig.Format, sinkConfig.Addr, sinkConfig.Interval)
+	}
+
+	if s.config.ReportWebhook != "" {
+		go s.sendReports(ctx, report.NewWebhook(s.config.ReportWebhook, s.config.ReportSecret), s.config.ReportInterval)
+		s.log.Printf("Sending activity reports every %s\n", s.config.ReportInterval)
+	}
+
+	if s.config.NotifyURL != "" {
+		s.notifier = report.NewNotifier(s.config.NotifyURL, s.config.ReportSecret, s.config.LogTag, s.log.Printf)
+		defer s.notifier.Close(notifyCloseTimeout)
+	}
+
 	// Set up notice handling FIRST - before any psiphon calls
 	if err := psiphon.SetNoticeWriter(psiphon.NewNoticeReceiver(
 		func(notice []byte) {
@@ -135,20 +275,8 @@ func (s *Service) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to set notice writer: %w", err)
 	}
 
-	// Create Psiphon configuration
-	psiphonConfig, err := s.createPsiphonConfig()
-	if err != nil {
-		return fmt.Errorf("failed to create psiphon config: %w", err)
-	}
-
-	bandwidthStr := "unlimited"
-	if s.config.BandwidthBytesPerSecond > 0 {
-		bandwidthStr = fmt.Sprintf("%.0f Mbps", float64(s.config.BandwidthBytesPerSecond)*8/1000/1000)
-	}
-	fmt.Printf("Starting Psiphon Conduit (Max Clients: %d, Bandwidth: %s)\n", s.config.MaxClients, bandwidthStr)
-
 	// Open the data store
-	err = psiphon.OpenDataStore(&psiphon.Config{
+	err := psiphon.OpenDataStore(&psiphon.Config{
 		DataRootDirectory: s.config.DataDir,
 	})
 	if err != nil {
@@ -156,25 +284,196 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 	defer psiphon.CloseDataStore()
 
-	// Create and run controller
-	s.controller, err = psiphon.NewController(psiphonConfig)
+	s.mu.RLock()
+	maxClients, bandwidth := s.maxClients, s.bandwidth
+	s.mu.RUnlock()
+	s.notify(report.EventStarted, fmt.Sprintf("Conduit started (max clients %d, bandwidth %s)", maxClients, formatBandwidth(bandwidth)))
+
+	// Idle restarts and limit changes replace only the controller, so geo,
+	// reports, metrics, the log and notifications carry on across them
+	for {
+		err := s.runController(ctx)
+		switch {
+		case ctx.Err() != nil:
+			s.notify(report.EventStopping, "Conduit is stopping")
+			return nil
+		case errors.Is(err, errLimitsChanged):
+			// Start the next controller with the new limits right away
+		case errors.Is(err, errIdleRestart):
+			select {
+			case <-ctx.Done():
+				s.notify(report.EventStopping, "Conduit is stopping")
+				return nil
+			case <-time.After(idleRestartPause):
+			}
+		case err != nil:
+			s.notify(report.EventDisconnected, fmt.Sprintf("Conduit stopped on an error: %v", err))
+			return err
+		default:
+			s.notify(report.EventDisconnected, "Conduit stopped unexpectedly")
+			return nil
+		}
+	}
+}
+
+// runController creates a controller with the current limits and runs it
+// until ctx is done, SetLimits changes the limits or, with idle restart,
+// the proxy has been idle too long
+func (s *Service) runController(ctx context.Context) error {
+	runCtx, stopRun := context.WithCancel(ctx)
+	defer stopRun()
+
+	s.mu.Lock()
+	maxClients, bandwidth := s.maxClients, s.bandwidth
+	s.stopRun = stopRun
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.stopRun = nil
+		s.mu.Unlock()
+	}()
+
+	psiphonConfig, err := s.createPsiphonConfig(maxClients, bandwidth)
+	if err != nil {
+		return fmt.Errorf("failed to create psiphon config: %w", err)
+	}
+
+	s.log.Printf("Starting Psiphon Conduit (Max Clients: %d, Bandwidth: %s)\n", maxClients, formatBandwidth(bandwidth))
+
+	controller, err := psiphon.NewController(psiphonConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
 	}
 
-	// If idle restart is enabled, run the controller with idle monitoring
+	// A new controller starts with no clients and counts its byte totals
+	// from zero, so they are added to what earlier controllers moved
+	s.mu.Lock()
+	s.stats.ConnectingClients = 0
+	s.stats.ConnectedClients = 0
+	s.stats.IsLive = false
+	s.brokerFails = 0
+	s.bytesUpBase, s.bytesDownBase = s.stats.TotalBytesUp, s.stats.TotalBytesDown
+	if s.metrics != nil {
+		s.metrics.SetIsLive(false)
+	}
+	s.updateMetrics()
+	s.mu.Unlock()
+
+	// Reset the controller's connections when the network changes. The
+	// watcher is scoped to this controller.
+	if s.config.NetworkWatch {
+		watchCtx, cancelWatch := context.WithCancel(runCtx)
+		defer cancelWatch()
+		go netwatch.Watch(watchCtx, func() {
+			s.log.Printf("%s [NET-CHANGE] reconnecting\n", time.Now().Format("2006-01-02 15:04:05"))
+			controller.NetworkChanged()
+		})
+	}
+
+	// Run the controller (blocks until context is cancelled), with idle
+	// monitoring if idle restart is enabled
+	var runErr error
 	if s.config.IdleRestart > 0 {
-		return s.runWithIdleMonitoring(ctx)
+		runErr = s.runWithIdleMonitoring(runCtx, controller)
+	} else {
+		controller.Run(runCtx)
+	}
+	if runErr == nil && runCtx.Err() != nil && ctx.Err() == nil {
+		return errLimitsChanged
 	}
+	return runErr
+}
+
+// Log returns the service's logger, so callers' messages go to the same
+// place as the service's own: stdout, the journal or the system log
+func (s *Service) Log() *logging.Throttle {
+	return s.log
+}
 
-	// Run the controller (blocks until context is cancelled)
-	s.controller.Run(ctx)
+// SetLimits changes max clients and the per-client bandwidth limit in bytes
+// per second (0 = unlimited). Tunnel-core reads the limits only when a
+// controller starts, so a running controller is replaced in-process. That
+// disconnects its clients, who must reconnect; geo, reports, metrics and
+// notifications carry on.
+func (s *Service) SetLimits(maxClients, bandwidthBytesPerSecond int) {
+	s.mu.Lock()
+	s.maxClients = maxClients
+	s.bandwidth = bandwidthBytesPerSecond
+	if s.metrics != nil {
+		s.metrics.SetConfig(maxClients, bandwidthBytesPerSecond)
+	}
+	stopRun := s.stopRun
+	s.mu.Unlock()
+
+	if stopRun != nil {
+		s.log.Printf("[WARN] Restarting the relay to apply the new limits; connected clients are disconnected and must reconnect\n")
+		stopRun()
+	}
+}
+
+// formatBandwidth formats a per-client limit in bytes per second
+func formatBandwidth(bytesPerSecond int) string {
+	if bytesPerSecond <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.0f Mbps", float64(bytesPerSecond)*8/1000/1000)
+}
+
+// setLive records whether the proxy can reach a broker. The log and
+// --notify-url hear about changes; a controller restart clears IsLive but
+// not the notified state, so restarts alone don't notify.
+func (s *Service) setLive(live bool) {
+	s.mu.Lock()
+	if live {
+		s.brokerFails = 0
+	}
+	changed := s.stats.IsLive != live
+	s.stats.IsLive = live
+	if changed && s.metrics != nil {
+		s.metrics.SetIsLive(live)
+	}
+	notify := s.notifiedLive != live
+	s.notifiedLive = live
+	s.mu.Unlock()
+
+	if changed && live {
+		s.log.Printf("[OK] Connected to Psiphon network\n")
+	}
+	if notify && live {
+		s.notify(report.EventConnected, "Conduit connected to the Psiphon network")
+	}
+	if notify && !live {
+		s.log.Printf("[WARN] Lost connection to the Psiphon broker, retrying\n")
+		s.notify(report.EventDisconnected, "Conduit lost its connection to the Psiphon broker")
+	}
+}
+
+// noteAnnounceError tracks failed announcements. Errors that carry an
+// answer from the broker show it is reachable; brokerFailureLimit other
+// failures in a row mean the connection is lost.
+func (s *Service) noteAnnounceError(errMsg string) {
+	if strings.Contains(errMsg, "no match") || strings.Contains(errMsg, "limited") || strings.Contains(errMsg, "must upgrade") {
+		s.setLive(true)
+		return
+	}
+	s.mu.Lock()
+	s.brokerFails++
+	lost := s.brokerFails >= brokerFailureLimit
+	s.mu.Unlock()
+	if lost {
+		s.setLive(false)
+	}
+}
 
-	return nil
+// notify sends a state change to --notify-url, if set
+func (s *Service) notify(event, message string) {
+	if s.notifier != nil {
+		s.notifier.Notify(event, message)
+	}
 }
 
 // createPsiphonConfig creates the Psiphon tunnel-core configuration
-func (s *Service) createPsiphonConfig() (*psiphon.Config, error) {
+func (s *Service) createPsiphonConfig(maxClients, bandwidth int) (*psiphon.Config, error) {
 	configJSON := make(map[string]interface{})
 
 	// Load base config from psiphon config file or embedded data
@@ -204,11 +503,11 @@ func (s *Service) createPsiphonConfig() (*psiphon.Config, error) {
 
 	// Inproxy mode settings - these override any values in the base config
 	configJSON["InproxyEnableProxy"] = true
-	configJSON["InproxyMaxClients"] = s.config.MaxClients
+	configJSON["InproxyMaxClients"] = maxClients
 	// Only set bandwidth limits if not unlimited (0 means unlimited)
-	if s.config.BandwidthBytesPerSecond > 0 {
-		configJSON["InproxyLimitUpstreamBytesPerSecond"] = s.config.BandwidthBytesPerSecond
-		configJSON["InproxyLimitDownstreamBytesPerSecond"] = s.config.BandwidthBytesPerSecond
+	if bandwidth > 0 {
+		configJSON["InproxyLimitUpstreamBytesPerSecond"] = bandwidth
+		configJSON["InproxyLimitDownstreamBytesPerSecond"] = bandwidth
 	}
 	configJSON["InproxyProxySessionPrivateKey"] = s.config.PrivateKeyBase64
 
@@ -243,10 +542,13 @@ func (s *Service) createPsiphonConfig() (*psiphon.Config, error) {
 		return nil, fmt.Errorf("failed to commit config: %w", err)
 	}
 
-	// Set up geo tracking callback if enabled
-	if s.geoCollector != nil {
+	// Set up connection callbacks for geo tracking and tracing (-vvv)
+	if s.geoCollector != nil || s.config.Verbosity >= 3 {
 		psiphonConfig.OnInproxyConnectionEstablished = func(local, remote inproxy.ConnectionStats) {
-			if remote.IP == "" {
+			if s.config.Verbosity >= 3 {
+				s.log.Printf("[TRACE] Client connected (candidate: %s)\n", remote.CandidateType)
+			}
+			if s.geoCollector == nil || remote.IP == "" {
 				return
 			}
 			if remote.CandidateType == "relay" {
@@ -256,7 +558,14 @@ func (s *Service) createPsiphonConfig() (*psiphon.Config, error) {
 			}
 		}
 		psiphonConfig.OnInproxyConnectionClosed = func(remote *inproxy.ConnectionStats, bw *inproxy.BandwidthStats) {
-			if remote == nil || remote.IP == "" || bw == nil {
+			if remote == nil || bw == nil {
+				return
+			}
+			if s.config.Verbosity >= 3 {
+				s.log.Printf("[TRACE] Client disconnected (candidate: %s, up: %s, down: %s)\n",
+					remote.CandidateType, formatBytes(bw.BytesUp), formatBytes(bw.BytesDown))
+			}
+			if s.geoCollector == nil || remote.IP == "" {
 				return
 			}
 			if remote.CandidateType == "relay" {
@@ -296,6 +605,46 @@ func (s *Service) getIdleSecondsFloat() float64 {
 	return s.calcIdleSeconds()
 }
 
+// getUniqueClients returns the estimated number of distinct clients (for Prometheus scrape)
+func (s *Service) getUniqueClients() float64 {
+	s.mu.RLock()
+	collector := s.geoCollector
+	s.mu.RUnlock()
+	if collector == nil {
+		return 0
+	}
+	return float64(collector.EstimatedUniqueClients())
+}
+
+// getConnectionRate returns new client IPs per second (for Prometheus scrape)
+func (s *Service) getConnectionRate() float64 {
+	s.mu.RLock()
+	collector := s.geoCollector
+	s.mu.RUnlock()
+	if collector == nil {
+		return 0
+	}
+	return collector.GetConnectionRate()
+}
+
+// getClientsByCountry returns live clients per country code, omitting
+// countries with none (for Prometheus scrape)
+func (s *Service) getClientsByCountry() map[string]int {
+	s.mu.RLock()
+	collector := s.geoCollector
+	s.mu.RUnlock()
+	if collector == nil {
+		return nil
+	}
+	clients := make(map[string]int)
+	for _, r := range collector.GetResults() {
+		if r.Count > 0 {
+			clients[r.Code] = r.Count
+		}
+	}
+	return clients
+}
+
 // calcIdleSeconds calculates idle time. Must be called with lock held.
 func (s *Service) calcIdleSeconds() float64 {
 	if s.stats.ConnectingClients > 0 || s.stats.ConnectedClients > 0 {
@@ -341,6 +690,10 @@ func (s *Service) handleNotice(notice []byte) {
 		if s.stats.ConnectingClients > 0 || s.stats.ConnectedClients > 0 {
 			s.stats.LastActiveTime = time.Now()
 		}
+		// A new client means the broker matched us
+		if s.stats.ConnectingClients > prevConnecting {
+			s.brokerFails = 0
+		}
 
 		// Log if client counts changed
 		if s.stats.ConnectingClients != prevConnecting || s.stats.ConnectedClients != prevConnected {
@@ -351,6 +704,11 @@ func (s *Service) handleNotice(notice []byte) {
 
 		s.mu.Unlock()
 
+		// -vvv: show every activity tick
+		if s.config.Verbosity >= 3 {
+			s.log.Printf("[TRACE] %s: %v\n", noticeData.NoticeType, noticeData.Data)
+		}
+
 	case "InproxyProxyTotalActivity":
 		// Update stats from total activity notices
 		s.mu.Lock()
@@ -363,16 +721,20 @@ func (s *Service) handleNotice(notice []byte) {
 			s.stats.ConnectedClients = int(v)
 		}
 		if v, ok := noticeData.Data["totalBytesUp"].(float64); ok {
-			s.stats.TotalBytesUp = int64(v)
+			s.stats.TotalBytesUp = s.bytesUpBase + int64(v)
 		}
 		if v, ok := noticeData.Data["totalBytesDown"].(float64); ok {
-			s.stats.TotalBytesDown = int64(v)
+			s.stats.TotalBytesDown = s.bytesDownBase + int64(v)
 		}
 
 		// Track last active time for idle calculation
 		if s.stats.ConnectingClients > 0 || s.stats.ConnectedClients > 0 {
 			s.stats.LastActiveTime = time.Now()
 		}
+		// A new client means the broker matched us
+		if s.stats.ConnectingClients > prevConnecting {
+			s.brokerFails = 0
+		}
 
 		// Log if client counts changed
 		if s.stats.ConnectingClients != prevConnecting || s.stats.ConnectedClients != prevConnected {
@@ -383,63 +745,64 @@ func (s *Service) handleNotice(notice []byte) {
 
 		s.mu.Unlock()
 
+		// -vvv: show every activity tick
+		if s.config.Verbosity >= 3 {
+			s.log.Printf("[TRACE] %s: %v\n", noticeData.NoticeType, noticeData.Data)
+		}
+
 	case "Info":
 		// Check for broker connection status
 		if msg, ok := noticeData.Data["message"].(string); ok {
 			if strings.HasPrefix(msg, "inproxy: selected broker ") {
-				s.mu.Lock()
-				if !s.stats.IsLive {
-					s.stats.IsLive = true
-					if s.metrics != nil {
-						s.metrics.SetIsLive(true)
-					}
-					s.mu.Unlock()
-					fmt.Println("[OK] Connected to Psiphon network")
-				} else {
-					s.mu.Unlock()
-				}
+				s.setLive(true)
 				if s.config.Verbosity >= 2 {
-					fmt.Printf("[DEBUG] Info: %v\n", noticeData.Data)
+					s.log.Printf("[DEBUG] Info: %v\n", noticeData.Data)
 				}
 			} else if s.config.Verbosity >= 1 {
 				// -v: show info messages except noisy announcement requests
 				if msg != "announcement request" {
-					fmt.Printf("[INFO] %s\n", msg)
+					s.log.Printf("[INFO] %s\n", msg)
 				} else if s.config.Verbosity >= 2 {
 					// -vv: show everything including announcement requests
-					fmt.Printf("[DEBUG] Info: %v\n", noticeData.Data)
+					s.log.Printf("[DEBUG] Info: %v\n", noticeData.Data)
 				}
 			}
 		}
 
 	case "InproxyMustUpgrade":
-		fmt.Println("\nWARNING: A newer version of Conduit is required. Please upgrade.")
+		s.log.Printf("\nWARNING: A newer version of Conduit is required. Please upgrade.\n")
 
 	case "Error":
+		if msg, _ := noticeData.Data["message"].(string); msg == "proxy client failed" {
+			errMsg, _ := noticeData.Data["error"].(string)
+			s.noteAnnounceError(errMsg)
+		}
+
 		// Handle errors based on verbosity
 		if s.config.Verbosity >= 1 {
 			if errMsg, ok := noticeData.Data["error"].(string); ok {
 				// -v: filter out noisy "limited" errors (normal when no clients available)
 				if s.config.Verbosity >= 2 || !isNoisyError(errMsg) {
-					fmt.Printf("[ERROR] %s\n", errMsg)
+					s.log.Printf("[ERROR] %s\n", errMsg)
 				}
 			} else if s.config.Verbosity >= 2 {
-				fmt.Printf("[DEBUG] Error: %v\n", noticeData.Data)
+				s.log.Printf("[DEBUG] Error: %v\n", noticeData.Data)
 			}
 		}
 
 	default:
 		// Only show debug output in debug mode (-vv)
 		if s.config.Verbosity >= 2 {
-			// Filter out noisy warnings that are expected in inproxy mode
-			if noticeData.NoticeType == "Warning" {
+			// Filter out noisy warnings that are expected in inproxy mode,
+			// unless tracing (-vvv)
+			if noticeData.NoticeType == "Warning" && s.config.Verbosity < 3 {
 				if msg, ok := noticeData.Data["message"].(string); ok {
 					if msg == "tactics request aborted: no capable servers" {
 						return
 					}
 				}
 			}
-			fmt.Printf("[DEBUG] %s: %v\n", noticeData.NoticeType, noticeData.Data)
+			s.log.Printf("[DEBUG] %s: %v\n", noticeData.NoticeType, noticeData.Data)
 		}
 	}
 }
@@ -465,7 +828,27 @@ func isNoisyError(errMsg string) bool {
 // logStats logs the current proxy statistics (must be called with lock held)
 func (s *Service) logStats() {
 	uptime := time.Since(s.stats.StartTime).Truncate(time.Second)
-	fmt.Printf("%s [STATS] Connecting: %d | Connected: %d | Up: %s | Down: %s | Uptime: %s\n",
+
+	var geoResults []geo.Result
+	fields := map[string]string{
+		"CONNECTING":     strconv.Itoa(s.stats.ConnectingClients),
+		"CLIENTS":        strconv.Itoa(s.stats.ConnectedClients),
+		"UP_BYTES":       strconv.FormatInt(s.stats.TotalBytesUp, 10),
+		"DOWN_BYTES":     strconv.FormatInt(s.stats.TotalBytesDown, 10),
+		"UPTIME_SECONDS": strconv.FormatInt(int64(uptime.Seconds()), 10),
+	}
+	if s.geoCollector != nil {
+		geoResults = s.geoCollector.GetResults()
+		// Results are sorted by live clients, so the first real country leads
+		for _, r := range geoResults {
+			if r.Code != "RELAY" && r.Count > 0 {
+				fields["COUNTRY"] = r.Code
+				break
+			}
+		}
+	}
+
+	s.log.PrintFields(fields, "%s [STATS] Connecting: %d | Connected: %d | Up: %s | Down: %s | Uptime: %s\n",
 		time.Now().Format("2006-01-02 15:04:05"),
 		s.stats.ConnectingClients,
 		s.stats.ConnectedClients,
@@ -474,38 +857,89 @@ func (s *Service) logStats() {
 		formatDuration(uptime),
 	)
 
+	statsJSON := s.statsJSON()
+	if s.config.LogStatsJSON {
+		if line, err := json.Marshal(statsJSON); err == nil {
+			s.log.Printf("[STATS-JSON] %s\n", line)
+		}
+	}
+
+	// Keep a short history for crash reports
+	s.recentStats = append(s.recentStats, statsJSON)
+	if len(s.recentStats) > maxRecentStats {
+		s.recentStats = s.recentStats[len(s.recentStats)-maxRecentStats:]
+	}
+
 	// Write stats to file if configured (copy data while locked, write async)
 	if s.config.StatsFile != "" {
-		statsJSON := StatsJSON{
-			ConnectingClients: s.stats.ConnectingClients,
-			ConnectedClients:  s.stats.ConnectedClients,
-			TotalBytesUp:      s.stats.TotalBytesUp,
-			TotalBytesDown:    s.stats.TotalBytesDown,
-			UptimeSeconds:     int64(time.Since(s.stats.StartTime).Seconds()),
-			IdleSeconds:       int64(s.calcIdleSeconds()),
-			IsLive:            s.stats.IsLive,
-			Timestamp:         time.Now().Format(time.RFC3339),
-		}
 		if s.geoCollector != nil {
-			statsJSON.Geo = s.geoCollector.GetResults()
+			statsJSON.UniqueClients = s.geoCollector.EstimatedUniqueClients()
+			statsJSON.ConnectionRate = s.geoCollector.GetConnectionRate()
+			statsJSON.Geo = geoResults
+			statsJSON.GeoCities = s.geoCollector.GetResultsByCity()
+			statsJSON.GeoASNs = s.geoCollector.GetASNResults()
 		}
 		go s.writeStatsToFile(statsJSON)
 	}
 }
 
+// statsJSON returns the current stats without geo data (must be called with lock held)
+func (s *Service) statsJSON() StatsJSON {
+	return StatsJSON{
+		ConnectingClients: s.stats.ConnectingClients,
+		ConnectedClients:  s.stats.ConnectedClients,
+		TotalBytesUp:      s.stats.TotalBytesUp,
+		TotalBytesDown:    s.stats.TotalBytesDown,
+		UptimeSeconds:     int64(time.Since(s.stats.StartTime).Seconds()),
+		IdleSeconds:       int64(s.calcIdleSeconds()),
+		IsLive:            s.stats.IsLive,
+		Ephemeral:         s.config.Ephemeral,
+		Timestamp:         time.Now().Format(time.RFC3339),
+	}
+}
+
 // writeStatsToFile writes stats to the configured JSON file asynchronously
 func (s *Service) writeStatsToFile(statsJSON StatsJSON) {
 	data, err := json.MarshalIndent(statsJSON, "", "  ")
 	if err != nil {
 		if s.config.Verbosity >= 1 {
-			fmt.Printf("[ERROR] Failed to marshal stats: %v\n", err)
+			s.log.Printf("[ERROR] Failed to marshal stats: %v\n", err)
 		}
 		return
 	}
 
 	if err := os.WriteFile(s.config.StatsFile, data, 0644); err != nil {
 		if s.config.Verbosity >= 1 {
-			fmt.Printf("[ERROR] Failed to write stats file: %v\n", err)
+			s.log.Printf("[ERROR] Failed to write stats file: %v\n", err)
+		}
+	}
+}
+
+// pushStats sends a stats sample to the sink every interval until ctx is done
+func (s *Service) pushStats(ctx context.Context, sink statsink.Sink, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.RLock()
+			sample := statsink.Sample{
+				Time:              time.Now(),
+				ConnectingClients: s.stats.ConnectingClients,
+				ConnectedClients:  s.stats.ConnectedClients,
+				BytesUp:           s.stats.TotalBytesUp,
+				BytesDown:         s.stats.TotalBytesDown,
+			}
+			s.mu.RUnlock()
+			if s.geoCollector != nil {
+				sample.Geo = s.geoCollector.GetResults()
+			}
+			if err := sink.Send(sample); err != nil && s.config.Verbosity >= 1 {
+				s.log.Printf("[ERROR] Failed to push stats: %v\n", err)
+			}
 		}
 	}
 }
@@ -524,6 +958,104 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%ds", s)
 }
 
+// logGeoEvents logs the first client from each country until the
+// collector is stopped
+func (s *Service) logGeoEvents(events <-chan geo.Event) {
+	for event := range events {
+		if event.Kind == geo.EventNewCountry {
+			s.log.Printf("[GEO] First client from %s (%s)\n", event.Country, event.Code)
+		}
+	}
+}
+
+// sendReports samples connected clients every minute and posts a summary
+// of each interval to the report webhook
+func (s *Service) sendReports(ctx context.Context, webhook *report.Webhook, interval time.Duration) {
+	sampleTicker := time.NewTicker(time.Minute)
+	defer sampleTicker.Stop()
+	reportTicker := time.NewTicker(interval)
+	defer reportTicker.Stop()
+
+	var tracker report.Tracker
+	var prevGeo geo.Snapshot
+	if s.geoCollector != nil {
+		prevGeo = s.geoCollector.Snapshot()
+	}
+	periodStart := time.Now()
+	s.mu.RLock()
+	lastUp, lastDown := s.stats.TotalBytesUp, s.stats.TotalBytesDown
+	s.mu.RUnlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sampleTicker.C:
+			s.mu.RLock()
+			tracker.Observe(s.stats.ConnectedClients)
+			s.mu.RUnlock()
+		case now := <-reportTicker.C:
+			s.mu.RLock()
+			summary := report.Summary{
+				PeriodStart:   periodStart.Format(time.RFC3339),
+				PeriodEnd:     now.Format(time.RFC3339),
+				UptimeSeconds: int64(now.Sub(s.stats.StartTime).Seconds()),
+				BytesUp:       s.stats.TotalBytesUp - lastUp,
+				BytesDown:     s.stats.TotalBytesDown - lastDown,
+				PeakClients:   tracker.Peak(),
+				AvgClients:    tracker.Avg(),
+			}
+			lastUp, lastDown = s.stats.TotalBytesUp, s.stats.TotalBytesDown
+			s.mu.RUnlock()
+			if s.geoCollector != nil {
+				curGeo := s.geoCollector.Snapshot()
+				summary.TopCountries = report.TopCountries(prevGeo, curGeo, 5)
+				prevGeo = curGeo
+			}
+			tracker.Reset()
+			periodStart = now
+
+			// Deliver in the background so retries don't stall sampling
+			go func() {
+				if err := webhook.Send(ctx, summary); err != nil {
+					s.log.Printf("[ERROR] %v\n", err)
+				}
+			}()
+		}
+	}
+}
+
+// ReloadGeoDB reopens the GeoIP database files without restarting
+func (s *Service) ReloadGeoDB() error {
+	s.mu.RLock()
+	collector := s.geoCollector
+	s.mu.RUnlock()
+	if collector == nil {
+		return errors.New("geo tracking is not enabled")
+	}
+	loaded, err := collector.ReloadDB()
+	for _, db := range loaded {
+		s.log.Printf("[GEO] Reloaded %s (built %s)\n", db.File, db.Built.UTC().Format("2006-01-02"))
+	}
+	return err
+}
+
+// StatsSnapshot returns current statistics in the stats file format, including geo
+func (s *Service) StatsSnapshot() StatsJSON {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statsJSON := s.statsJSON()
+	if s.geoCollector != nil {
+		statsJSON.UniqueClients = s.geoCollector.EstimatedUniqueClients()
+		statsJSON.ConnectionRate = s.geoCollector.GetConnectionRate()
+		statsJSON.Geo = s.geoCollector.GetResults()
+		statsJSON.GeoCities = s.geoCollector.GetResultsByCity()
+		statsJSON.GeoASNs = s.geoCollector.GetASNResults()
+	}
+	return statsJSON
+}
+
 // GetStats returns current statistics
 func (s *Service) GetStats() Stats {
 	s.mu.RLock()
@@ -546,8 +1078,10 @@ func formatBytes(bytes int64) string {
 }
 
 // runWithIdleMonitoring runs the controller with idle time monitoring.
-// Returns ErrIdleRestart if idle timeout is reached, nil if context is cancelled.
-func (s *Service) runWithIdleMonitoring(ctx context.Context) error {
+// Returns errIdleRestart if idle timeout is reached, nil if context is cancelled.
+func (s *Service) runWithIdleMonitoring(ctx context.Context, controller *psiphon.Controller) error {
+	started := time.Now()
+
 	// Create a cancellable context for the controller
 	controllerCtx, cancelController := context.WithCancel(ctx)
 	defer cancelController()
@@ -557,7 +1091,7 @@ func (s *Service) runWithIdleMonitoring(ctx context.Context) error {
 
 	// Run controller in goroutine
 	go func() {
-		s.controller.Run(controllerCtx)
+		controller.Run(controllerCtx)
 		close(controllerDone)
 	}()
 
@@ -578,13 +1112,14 @@ func (s *Service) runWithIdleMonitoring(ctx context.Context) error {
 			return nil
 
 		case <-ticker.C:
-			idleSeconds := s.getIdleSecondsFloat()
+			// Idle time counts from this controller's start at most
+			idleSeconds := min(s.getIdleSecondsFloat(), time.Since(started).Seconds())
 			if idleSeconds >= s.config.IdleRestart.Seconds() {
-				fmt.Printf("\n[IDLE] No activity for %s, restarting to refresh connections...\n",
+				s.log.Printf("\n[IDLE] No activity for %s, restarting to refresh connections...\n",
 					formatDuration(time.Duration(idleSeconds)*time.Second))
 				cancelController()
 				<-controllerDone
-				return ErrIdleRestart
+				return errIdleRestart
 			}
 		}
 	}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package conduit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/config"
+	"github.com/Psiphon-Inc/conduit/cli/internal/logging"
+)
+
+func TestBrokerConnectionTracking(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	s := &Service{config: &config.Config{}, stats: &Stats{}, log: logging.NewThrottle(devNull, 0)}
+	notice := func(message, errMsg string) {
+		s.handleNotice([]byte(`{"noticeType":"Error","data":{"message":"` + message + `","error":"` + errMsg + `"}}`))
+	}
+
+	s.handleNotice([]byte(`{"noticeType":"Info","data":{"message":"inproxy: selected broker abc"}}`))
+	if !s.GetStats().IsLive || !s.notifiedLive {
+		t.Fatal("selecting a broker didn't mark the proxy live")
+	}
+
+	// A failure followed by a broker answer doesn't count as lost
+	notice("proxy client failed", "inproxy.(*Proxy).proxyOneClient: dial failed")
+	notice("proxy client failed", "inproxy.(*Proxy).proxyOneClient: no match")
+	notice("proxy client failed", "inproxy.(*Proxy).proxyOneClient: dial failed")
+	if !s.GetStats().IsLive {
+		t.Fatal("failures separated by a broker answer marked the broker lost")
+	}
+
+	notice("proxy client failed", "inproxy.(*Proxy).proxyOneClient: dial failed")
+	if s.GetStats().IsLive || s.notifiedLive {
+		t.Fatalf("%d failures in a row didn't mark the broker lost", brokerFailureLimit)
+	}
+
+	notice("proxy client failed", "inproxy.(*Proxy).proxyOneClient: limited")
+	if !s.GetStats().IsLive {
+		t.Fatal("a broker answer didn't mark the proxy live again")
+	}
+}
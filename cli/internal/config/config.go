@@ -23,7 +23,9 @@ package config
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"time"
@@ -36,7 +38,9 @@ import (
 const (
 	DefaultMaxClients    = 50
 	DefaultBandwidthMbps = 40.0
+	MaxBandwidthMbps     = 40.0 // Highest per-peer bandwidth limit
 	MaxClientsLimit      = 1000
+	DefaultLogRateLimit  = 20   // Max log lines per second from the service
 	UnlimitedBandwidth   = -1.0 // Special value for no bandwidth limit
 
 	// File names for persisted data
@@ -51,10 +55,34 @@ type Options struct {
 	MaxClients        int
 	BandwidthMbps     float64
 	BandwidthSet      bool
-	Verbosity         int    // 0=normal, 1=verbose, 2+=debug
+	Verbosity         int    // 0=normal, 1=verbose, 2=debug, 3+=trace
 	StatsFile         string // Path to write stats JSON file (empty = disabled)
 	MetricsAddr       string // Address for Prometheus metrics endpoint (empty = disabled)
 	IdleRestart       time.Duration
+	GeoEnabled        bool          // Track client locations
+	GeoEstimateUnique bool          // Estimate unique clients with HyperLogLog instead of exact sets
+	GeoCity           bool          // Also track clients by region and city
+	GeoASN            bool          // Also track clients by network (autonomous system)
+	GeoWindow         time.Duration // Count unique clients over this sliding window (0 = since start)
+	GeoPersist        bool          // Keep per-country totals across restarts
+	GeoRateWindow     time.Duration // Period the new-connection rate is averaged over (0 = default)
+	GeoObservations   string        // JSON Lines file for per-connection observations ("-" = stdout, empty = disabled)
+	LogRateLimit      int           // Max log lines per second (0 = unlimited)
+	LogTag            string        // Prefix for every log line, e.g. "conduit-eu" (empty = none)
+	LogStatsJSON      bool          // Also log each stats update as a [STATS-JSON] line
+	LogSyslog         bool          // Send logs to syslog (Event Log on Windows) instead of stdout
+	SyslogFacility    string        // Syslog facility, e.g. "daemon" or "local0"
+	SyslogTag         string        // Syslog program name / Event Log source
+	StatsSink         string        // statsd/InfluxDB sink URL (empty = disabled)
+	ReportWebhook     string        // URL to POST periodic summaries to (empty = disabled)
+	ReportSecret      string        // HMAC key for signing report requests (empty = unsigned)
+	ReportInterval    time.Duration
+	NotifyURL         string // URL to POST service state changes to (empty = disabled)
+	SafeMode          bool   // Disable optional subsystems for this run
+	Ephemeral         bool   // Generate an in-memory key that is never saved
+	AutoTune          bool   // Derive an unset max-clients from host resources instead of the fixed default
+	NetworkWatch      bool   // Reconnect when the host's network changes
+	ReadOnly          bool   // Resolve without locking or writing to the data directory, e.g. to display it
 }
 
 // Config represents the validated configuration for the Conduit service
@@ -66,10 +94,35 @@ type Config struct {
 	DataDir                 string
 	PsiphonConfigPath       string
 	PsiphonConfigData       []byte // Embedded config data (if used)
-	Verbosity               int    // 0=normal, 1=verbose, 2+=debug
+	Verbosity               int    // 0=normal, 1=verbose, 2=debug, 3+=trace
 	StatsFile               string // Path to write stats JSON file (empty = disabled)
 	MetricsAddr             string // Address for Prometheus metrics endpoint (empty = disabled)
 	IdleRestart             time.Duration
+	GeoEnabled              bool          // Track client locations
+	GeoEstimateUnique       bool          // Estimate unique clients with HyperLogLog instead of exact sets
+	GeoCity                 bool          // Also track clients by region and city
+	GeoASN                  bool          // Also track clients by network (autonomous system)
+	GeoWindow               time.Duration // Count unique clients over this sliding window (0 = since start)
+	GeoPersist              bool          // Keep per-country totals across restarts
+	GeoRateWindow           time.Duration // Period the new-connection rate is averaged over (0 = default)
+	GeoObservations         string        // JSON Lines file for per-connection observations ("-" = stdout, empty = disabled)
+	LogRateLimit            int           // Max log lines per second (0 = unlimited)
+	LogTag                  string        // Prefix for every log line, e.g. "conduit-eu" (empty = none)
+	LogStatsJSON            bool          // Also log each stats update as a [STATS-JSON] line
+	LogSyslog               bool          // Send logs to syslog (Event Log on Windows) instead of stdout
+	SyslogFacility          string        // Syslog facility, e.g. "daemon" or "local0"
+	SyslogTag               string        // Syslog program name / Event Log source
+	StatsSink               string        // statsd/InfluxDB sink URL (empty = disabled)
+	ReportWebhook           string        // URL to POST periodic summaries to (empty = disabled)
+	ReportSecret            string        // HMAC key for signing report requests (empty = unsigned)
+	ReportInterval          time.Duration
+	NotifyURL               string // URL to POST service state changes to (empty = disabled)
+	SafeMode                bool   // Optional subsystems were disabled for this run
+	Ephemeral               bool   // Identity exists only for this process
+	AutoTuned               string // How the auto-tuned max-clients was derived (empty if not tuned)
+	NetworkWatch            bool   // Reconnect when the host's network changes
+
+	lock *dirLock
 }
 
 // persistedKey represents the key data saved to disk
@@ -84,12 +137,40 @@ func LoadOrCreate(opts Options) (*Config, error) {
 	if opts.DataDir == "" {
 		opts.DataDir = "./data"
 	}
-	if err := os.MkdirAll(opts.DataDir, 0700); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
-	}
+	var lock *dirLock
+	var err error
+	if !opts.ReadOnly {
+		if err := os.MkdirAll(opts.DataDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create data directory: %w", err)
+		}
 
-	// Try to load existing key, or generate new one
-	keyPair, privateKeyBase64, err := loadOrCreateKey(opts.DataDir, opts.Verbosity > 0)
+		// Hold the data directory for the life of the process
+		lock, err = lockDataDir(opts.DataDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	locked := false
+	defer func() {
+		if !locked {
+			lock.release()
+		}
+	}()
+
+	// Try to load existing key, or generate new one. Ephemeral keys never
+	// touch the data directory.
+	var keyPair *crypto.KeyPair
+	var privateKeyBase64 string
+	if opts.Ephemeral {
+		keyPair, _, privateKeyBase64, err = generateKey()
+	} else if opts.ReadOnly {
+		// No key yet is fine: one is created on the first real start
+		if keyPair, privateKeyBase64, err = LoadKey(opts.DataDir); err != nil {
+			keyPair, privateKeyBase64, err = nil, "", nil
+		}
+	} else {
+		keyPair, privateKeyBase64, err = loadOrCreateKey(opts.DataDir, opts.Verbosity > 0)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load or create key: %w", err)
 	}
@@ -120,24 +201,38 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		}
 	}
 
+	// The max-clients default is fixed unless auto-tuning is on
+	defaultMaxClients := DefaultMaxClients
+	var resources Resources
+	if opts.AutoTune {
+		resources = DetectResources()
+		defaultMaxClients = resources.TunedMaxClients()
+	}
+
 	// Resolve max clients: flag > config > default
 	maxClients := opts.MaxClients
 	if maxClients == 0 && inproxyConfig.InproxyMaxClients != nil {
 		maxClients = *inproxyConfig.InproxyMaxClients
 	}
+	tuned := false
 	if maxClients == 0 {
-		maxClients = DefaultMaxClients
+		maxClients = defaultMaxClients
+		tuned = opts.AutoTune
 	}
 	if maxClients < 1 || maxClients > MaxClientsLimit {
-		return nil, fmt.Errorf("max-clients must be between 1 and %d", MaxClientsLimit)
+		source := "max-clients"
+		if opts.MaxClients == 0 {
+			source = "InproxyMaxClients in the Psiphon config"
+		}
+		return nil, fmt.Errorf("%s is %d, must be between 1 and %d", source, maxClients, MaxClientsLimit)
 	}
 
 	// Resolve bandwidth: flag > config > default
 	var bandwidthBytesPerSecond int
 	if opts.BandwidthSet {
 		bandwidthMbps := opts.BandwidthMbps
-		if bandwidthMbps != UnlimitedBandwidth && bandwidthMbps < 1 {
-			return nil, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+		if bandwidthMbps != UnlimitedBandwidth && (bandwidthMbps < 1 || bandwidthMbps > MaxBandwidthMbps) {
+			return nil, fmt.Errorf("bandwidth is %g Mbps, must be between 1 and %g Mbps per peer (or -1 for unlimited)", bandwidthMbps, MaxBandwidthMbps)
 		}
 		if bandwidthMbps == UnlimitedBandwidth {
 			bandwidthBytesPerSecond = 0
@@ -148,10 +243,12 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		hasUpstream := inproxyConfig.InproxyLimitUpstreamBytesPerSecond != nil
 		hasDownstream := inproxyConfig.InproxyLimitDownstreamBytesPerSecond != nil
 		if hasUpstream && *inproxyConfig.InproxyLimitUpstreamBytesPerSecond < 0 {
-			return nil, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+			return nil, fmt.Errorf("InproxyLimitUpstreamBytesPerSecond in the Psiphon config is %d, must be 0 (unlimited) or more",
+				*inproxyConfig.InproxyLimitUpstreamBytesPerSecond)
 		}
 		if hasDownstream && *inproxyConfig.InproxyLimitDownstreamBytesPerSecond < 0 {
-			return nil, fmt.Errorf("bandwidth must be at least 1 Mbps (or -1 for unlimited)")
+			return nil, fmt.Errorf("InproxyLimitDownstreamBytesPerSecond in the Psiphon config is %d, must be 0 (unlimited) or more",
+				*inproxyConfig.InproxyLimitDownstreamBytesPerSecond)
 		}
 		minPositive := 0
 		if hasUpstream && *inproxyConfig.InproxyLimitUpstreamBytesPerSecond > 0 {
@@ -171,7 +268,7 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		}
 	}
 
-	return &Config{
+	cfg := &Config{
 		KeyPair:                 keyPair,
 		PrivateKeyBase64:        privateKeyBase64,
 		MaxClients:              maxClients,
@@ -183,7 +280,170 @@ func LoadOrCreate(opts Options) (*Config, error) {
 		StatsFile:               opts.StatsFile,
 		MetricsAddr:             opts.MetricsAddr,
 		IdleRestart:             opts.IdleRestart,
-	}, nil
+		GeoEnabled:              opts.GeoEnabled,
+		GeoEstimateUnique:       opts.GeoEstimateUnique,
+		GeoCity:                 opts.GeoCity,
+		GeoASN:                  opts.GeoASN,
+		GeoWindow:               opts.GeoWindow,
+		GeoPersist:              opts.GeoPersist,
+		GeoRateWindow:           opts.GeoRateWindow,
+		GeoObservations:         opts.GeoObservations,
+		LogRateLimit:            opts.LogRateLimit,
+		LogTag:                  opts.LogTag,
+		LogStatsJSON:            opts.LogStatsJSON,
+		LogSyslog:               opts.LogSyslog,
+		SyslogFacility:          opts.SyslogFacility,
+		SyslogTag:               opts.SyslogTag,
+		StatsSink:               opts.StatsSink,
+		ReportWebhook:           opts.ReportWebhook,
+		ReportSecret:            opts.ReportSecret,
+		ReportInterval:          opts.ReportInterval,
+		NotifyURL:               opts.NotifyURL,
+		Ephemeral:               opts.Ephemeral,
+		NetworkWatch:            opts.NetworkWatch,
+		lock:                    lock,
+	}
+
+	if tuned {
+		cfg.AutoTuned = fmt.Sprintf("max-clients %d (from %s)", maxClients, resources)
+	}
+
+	// Safe mode isolates the core relay: it wins over every other source
+	if opts.SafeMode {
+		cfg.SafeMode = true
+		for _, reset := range safeModeResets {
+			reset(cfg)
+		}
+	}
+
+	locked = true
+	return cfg, nil
+}
+
+// safeModeResets turns off each optional subsystem, keyed by the start flag
+// that enables it
+var safeModeResets = map[string]func(*Config){
+	"verbose":                     func(c *Config) { c.Verbosity = 0 },
+	"geo":                         func(c *Config) { c.GeoEnabled = false },
+	"geo-estimate-unique":         func(c *Config) { c.GeoEstimateUnique = false },
+	"geo-city":                    func(c *Config) { c.GeoCity = false },
+	"geo-asn":                     func(c *Config) { c.GeoASN = false },
+	"geo-persist":                 func(c *Config) { c.GeoPersist = false },
+	"geo-observations":            func(c *Config) { c.GeoObservations = "" },
+	"metrics-addr":                func(c *Config) { c.MetricsAddr = "" },
+	"stats-sink":                  func(c *Config) { c.StatsSink = "" },
+	"report-webhook":              func(c *Config) { c.ReportWebhook = "" },
+	"notify-url":                  func(c *Config) { c.NotifyURL = "" },
+	"log-syslog":                  func(c *Config) { c.LogSyslog = false },
+	"log-stats-json":              func(c *Config) { c.LogStatsJSON = false },
+	"reconnect-on-network-change": func(c *Config) { c.NetworkWatch = false },
+}
+
+// SafeModeClears reports whether safe mode overrides the named start flag
+func SafeModeClears(flag string) bool {
+	_, ok := safeModeResets[flag]
+	return ok
+}
+
+// BandwidthMbps returns the bandwidth limit in Mbps, or UnlimitedBandwidth
+func (c *Config) BandwidthMbps() float64 {
+	if c.BandwidthBytesPerSecond == 0 {
+		return UnlimitedBandwidth
+	}
+	return float64(c.BandwidthBytesPerSecond) * 8 / 1000 / 1000
+}
+
+// WithLimits returns a copy of the config with new client and bandwidth
+// limits, clamped to the allowed ranges. Notes describe any clamping.
+func (c *Config) WithLimits(maxClients int, bandwidthMbps float64) (*Config, []string) {
+	next := *c
+	next.AutoTuned = ""
+	var notes []string
+
+	if clamped := max(1, min(maxClients, MaxClientsLimit)); clamped != maxClients {
+		notes = append(notes, fmt.Sprintf("max-clients %d is out of range, using %d", maxClients, clamped))
+		maxClients = clamped
+	}
+	next.MaxClients = maxClients
+
+	if bandwidthMbps != UnlimitedBandwidth && bandwidthMbps < 1 {
+		notes = append(notes, fmt.Sprintf("bandwidth %g Mbps is below 1 Mbps, using 1", bandwidthMbps))
+		bandwidthMbps = 1
+	}
+	if bandwidthMbps > MaxBandwidthMbps {
+		notes = append(notes, fmt.Sprintf("bandwidth %g Mbps is above %g Mbps per peer, using %g", bandwidthMbps, MaxBandwidthMbps, MaxBandwidthMbps))
+		bandwidthMbps = MaxBandwidthMbps
+	}
+	if bandwidthMbps == UnlimitedBandwidth {
+		next.BandwidthBytesPerSecond = 0
+	} else {
+		next.BandwidthBytesPerSecond = int(bandwidthMbps * 1000 * 1000 / 8)
+	}
+
+	return &next, notes
+}
+
+// Close releases the data directory lock taken by LoadOrCreate.
+func (c *Config) Close() error {
+	return c.lock.release()
+}
+
+// ValidatePsiphonConfigPath checks that path names a readable regular file,
+// following symlinks, so a bad --psiphon-config fails early with a clear reason.
+func ValidatePsiphonConfigPath(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("psiphon config file not found: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot access psiphon config file %s: %w", path, err)
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, _ := os.Readlink(path)
+		info, err = os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("psiphon config %s is a symlink to %s, which does not exist", path, target)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot resolve psiphon config symlink %s: %w", path, err)
+		}
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("psiphon config %s is a directory, expected a JSON file", path)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("psiphon config %s is not a regular file", path)
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("permission denied reading psiphon config %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open psiphon config file: %w", err)
+	}
+	f.Close()
+
+	return nil
+}
+
+// generateKey creates a new key pair from a fresh mnemonic
+func generateKey() (*crypto.KeyPair, string, string, error) {
+	// Generate mnemonic for backup purposes
+	mnemonic, err := crypto.GenerateMnemonic()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to generate mnemonic: %w", err)
+	}
+
+	// Derive key from mnemonic
+	keyPair, err := crypto.DeriveKeyPairFromMnemonic(mnemonic, "")
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return keyPair, mnemonic, base64.RawStdEncoding.EncodeToString(keyPair.PrivateKey), nil
 }
 
 // loadOrCreateKey loads an existing key from disk or generates a new one
@@ -212,21 +472,11 @@ func loadOrCreateKey(dataDir string, verbose bool) (*crypto.KeyPair, string, err
 	}
 
 	// Generate new key
-
-	// Generate mnemonic for backup purposes
-	mnemonic, err := crypto.GenerateMnemonic()
+	keyPair, mnemonic, privateKeyBase64, err := generateKey()
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate mnemonic: %w", err)
+		return nil, "", err
 	}
 
-	// Derive key from mnemonic
-	keyPair, err := crypto.DeriveKeyPairFromMnemonic(mnemonic, "")
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to derive key: %w", err)
-	}
-
-	privateKeyBase64 := base64.RawStdEncoding.EncodeToString(keyPair.PrivateKey)
-
 	// Save to disk
 	pk := persistedKey{
 		Mnemonic:         mnemonic,
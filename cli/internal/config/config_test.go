@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -19,6 +21,183 @@ func bandwidthBytes(mbps float64) int {
 	return int(mbps * 1000 * 1000 / 8)
 }
 
+func TestLoadOrCreateSafeMode(t *testing.T) {
+	dataDir := t.TempDir()
+	cfg, err := LoadOrCreate(Options{
+		DataDir:           dataDir,
+		PsiphonConfigPath: writeTempConfig(t, dataDir, `{}`),
+		Verbosity:         2,
+		GeoEnabled:        true,
+		MetricsAddr:       "127.0.0.1:9090",
+		StatsSink:         "udp://127.0.0.1:8125",
+		NotifyURL:         "https://hooks.example.com/x",
+		LogSyslog:         true,
+		LogStatsJSON:      true,
+		NetworkWatch:      true,
+		SafeMode:          true,
+	})
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	defer cfg.Close()
+	if !cfg.SafeMode || cfg.Verbosity != 0 || cfg.GeoEnabled || cfg.MetricsAddr != "" || cfg.StatsSink != "" || cfg.NotifyURL != "" ||
+		cfg.LogSyslog || cfg.LogStatsJSON || cfg.NetworkWatch {
+		t.Fatalf("safe mode left optional subsystems enabled: %+v", cfg)
+	}
+}
+
+func TestLoadOrCreateEphemeral(t *testing.T) {
+	dataDir := t.TempDir()
+	opts := Options{
+		DataDir:           dataDir,
+		PsiphonConfigPath: writeTempConfig(t, dataDir, `{}`),
+		Ephemeral:         true,
+	}
+
+	first, err := LoadOrCreate(opts)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	first.Close()
+
+	if _, err := os.Stat(filepath.Join(dataDir, keyFileName)); !os.IsNotExist(err) {
+		t.Fatalf("ephemeral mode wrote a key file: %v", err)
+	}
+
+	second, err := LoadOrCreate(opts)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	defer second.Close()
+	if !second.Ephemeral || first.PrivateKeyBase64 == second.PrivateKeyBase64 {
+		t.Fatal("ephemeral mode reused an identity across runs")
+	}
+}
+
+func TestValidatePsiphonConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	file := writeTempConfig(t, dir, `{}`)
+
+	if err := ValidatePsiphonConfigPath(file); err != nil {
+		t.Fatalf("regular file rejected: %v", err)
+	}
+	if err := ValidatePsiphonConfigPath(dir); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Fatalf("directory: got %v", err)
+	}
+	if err := ValidatePsiphonConfigPath(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("missing file: got %v", err)
+	}
+
+	link := filepath.Join(dir, "link.json")
+	if err := os.Symlink(file, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := ValidatePsiphonConfigPath(link); err != nil {
+		t.Fatalf("symlink to file rejected: %v", err)
+	}
+
+	dangling := filepath.Join(dir, "dangling.json")
+	if err := os.Symlink(filepath.Join(dir, "gone.json"), dangling); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := ValidatePsiphonConfigPath(dangling); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("dangling symlink: got %v", err)
+	}
+}
+
+func TestLoadOrCreateDataDirLock(t *testing.T) {
+	dataDir := t.TempDir()
+	opts := Options{
+		DataDir:           dataDir,
+		PsiphonConfigPath: writeTempConfig(t, dataDir, `{}`),
+	}
+
+	cfg, err := LoadOrCreate(opts)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+
+	if _, err := LoadOrCreate(opts); err == nil {
+		t.Fatal("second LoadOrCreate succeeded while data dir was locked")
+	} else if !strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Fatalf("lock error does not name the holder: %v", err)
+	}
+
+	if err := cfg.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	cfg, err = LoadOrCreate(opts)
+	if err != nil {
+		t.Fatalf("LoadOrCreate after Close: %v", err)
+	}
+	cfg.Close()
+}
+
+func TestLoadOrCreateReadOnly(t *testing.T) {
+	dataDir := t.TempDir()
+	opts := Options{
+		DataDir:           dataDir,
+		PsiphonConfigPath: writeTempConfig(t, dataDir, `{"InproxyMaxClients": 75}`),
+	}
+
+	// Read-only works alongside a running instance and doesn't create a key
+	running, err := LoadOrCreate(Options{DataDir: dataDir, PsiphonConfigPath: opts.PsiphonConfigPath, Ephemeral: true})
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	defer running.Close()
+
+	opts.ReadOnly = true
+	cfg, err := LoadOrCreate(opts)
+	if err != nil {
+		t.Fatalf("read-only LoadOrCreate while locked: %v", err)
+	}
+	defer cfg.Close()
+	if cfg.KeyPair != nil {
+		t.Fatal("read-only LoadOrCreate generated a key")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, keyFileName)); !os.IsNotExist(err) {
+		t.Fatalf("read-only LoadOrCreate wrote a key file: %v", err)
+	}
+	if cfg.MaxClients != 75 {
+		t.Fatalf("MaxClients = %d, expected 75", cfg.MaxClients)
+	}
+}
+
+func TestLoadOrCreateRanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		configJSON string
+		opts       Options
+		expected   string
+	}{
+		{"max_clients_flag", `{}`, Options{MaxClients: 100000}, "max-clients is 100000, must be between 1 and 1000"},
+		{"max_clients_config", `{"InproxyMaxClients": 5000}`, Options{}, "InproxyMaxClients in the Psiphon config is 5000"},
+		{"bandwidth_flag", `{}`, Options{BandwidthSet: true, BandwidthMbps: 0}, "bandwidth is 0 Mbps, must be between 1 and 40 Mbps per peer"},
+		{"bandwidth_above_max", `{}`, Options{BandwidthSet: true, BandwidthMbps: 100}, "bandwidth is 100 Mbps, must be between 1 and 40 Mbps per peer"},
+		{"bandwidth_config", `{"InproxyLimitUpstreamBytesPerSecond": -5}`, Options{}, "InproxyLimitUpstreamBytesPerSecond in the Psiphon config is -5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dataDir := t.TempDir()
+			opts := test.opts
+			opts.DataDir = dataDir
+			opts.PsiphonConfigPath = writeTempConfig(t, dataDir, test.configJSON)
+
+			cfg, err := LoadOrCreate(opts)
+			if err == nil {
+				cfg.Close()
+				t.Fatal("expected a range error")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("error %q does not contain %q", err, test.expected)
+			}
+		})
+	}
+}
+
 func TestLoadOrCreatePrecedence(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -75,6 +254,7 @@ func TestLoadOrCreatePrecedence(t *testing.T) {
 			if err != nil {
 				t.Fatalf("LoadOrCreate: %v", err)
 			}
+			defer cfg.Close()
 
 			if cfg.MaxClients != test.expectedMaxClients {
 				t.Fatalf("MaxClients = %d, expected %d", cfg.MaxClients, test.expectedMaxClients)
@@ -85,3 +265,28 @@ func TestLoadOrCreatePrecedence(t *testing.T) {
 		})
 	}
 }
+
+func TestWithLimits(t *testing.T) {
+	cfg := &Config{MaxClients: 50, BandwidthBytesPerSecond: bandwidthBytes(40), AutoTuned: "max-clients 50"}
+
+	next, notes := cfg.WithLimits(5000, 0.5)
+	if next.MaxClients != MaxClientsLimit || next.BandwidthBytesPerSecond != bandwidthBytes(1) || len(notes) != 2 {
+		t.Fatalf("expected clamped limits with notes, got %d clients, %d B/s, %v", next.MaxClients, next.BandwidthBytesPerSecond, notes)
+	}
+	if cfg.MaxClients != 50 || next.AutoTuned != "" {
+		t.Fatal("WithLimits modified the original or kept the auto-tune note")
+	}
+
+	next, notes = cfg.WithLimits(200, 100)
+	if next.BandwidthBytesPerSecond != bandwidthBytes(MaxBandwidthMbps) || len(notes) != 1 {
+		t.Fatalf("expected bandwidth clamped to %g Mbps, got %d B/s, %v", MaxBandwidthMbps, next.BandwidthBytesPerSecond, notes)
+	}
+
+	next, notes = cfg.WithLimits(200, UnlimitedBandwidth)
+	if next.MaxClients != 200 || next.BandwidthBytesPerSecond != 0 || next.BandwidthMbps() != UnlimitedBandwidth || len(notes) != 0 {
+		t.Fatalf("unexpected limits %d clients, %d B/s, %v", next.MaxClients, next.BandwidthBytesPerSecond, notes)
+	}
+	if mbps := cfg.BandwidthMbps(); mbps != 40 {
+		t.Fatalf("BandwidthMbps = %g, expected 40", mbps)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the environment variable for each start flag
+const EnvPrefix = "CONDUIT_"
+
+// EnvName returns the environment variable for a flag, e.g. max-clients -> CONDUIT_MAX_CLIENTS
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// ConfigFileName is the settings file looked for when --config isn't given
+const ConfigFileName = "conduit.yaml"
+
+// ConfigFileSearchPath returns where a settings file is looked for, in order
+func ConfigFileSearchPath(dataDir string) []string {
+	paths := []string{filepath.Join(dataDir, ConfigFileName)}
+	if runtime.GOOS != "windows" {
+		paths = append(paths, filepath.Join("/etc/conduit", ConfigFileName))
+	}
+	return paths
+}
+
+// FindConfigFile returns the first settings file on the search path, or "" if there is none
+func FindConfigFile(dataDir string) string {
+	for _, path := range ConfigFileSearchPath(dataDir) {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ReadConfigFile reads a YAML settings file keyed by start flag names, e.g.
+//
+//	max-clients: 200
+//	geo: true
+//	report-interval: 12h
+//
+// Values are returned as strings, to be applied and validated as flag values.
+func ReadConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch value.(type) {
+		case string, bool, int, float64:
+			values[key] = fmt.Sprint(value)
+		case nil:
+			return nil, fmt.Errorf("%s: %s has no value", path, key)
+		default:
+			return nil, fmt.Errorf("%s: %s must be a single value, not a list or map", path, key)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	if name := EnvName("max-clients"); name != "CONDUIT_MAX_CLIENTS" {
+		t.Fatalf("EnvName = %q", name)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	if path := FindConfigFile(dir); path != "" && strings.HasPrefix(path, dir) {
+		t.Fatalf("found %s in an empty data dir", path)
+	}
+
+	path := filepath.Join(dir, ConfigFileName)
+	contents := "max-clients: 200\nbandwidth: 2.5\ngeo: true\nreport-interval: 12h\n"
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if found := FindConfigFile(dir); found != path {
+		t.Fatalf("FindConfigFile = %q, expected %q", found, path)
+	}
+
+	values, err := ReadConfigFile(path)
+	if err != nil {
+		t.Fatalf("ReadConfigFile: %v", err)
+	}
+	expected := map[string]string{"max-clients": "200", "bandwidth": "2.5", "geo": "true", "report-interval": "12h"}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("%s = %q, expected %q", key, values[key], value)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("geo:\n  - true\n"), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := ReadConfigFile(path); err == nil || !strings.Contains(err.Error(), "single value") {
+		t.Fatalf("expected list value to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const lockFileName = "conduit.lock"
+
+// errLocked is returned by lockFile when another process holds the lock
+var errLocked = errors.New("lock held by another process")
+
+// dirLock is an exclusive lock on a data directory, held until released
+type dirLock struct {
+	f *os.File
+}
+
+// lockDataDir takes an exclusive lock on the data directory so two conduit
+// processes can't share keys and state. The holder's pid is recorded in the
+// lock file for the error message.
+func lockDataDir(dataDir string) (*dirLock, error) {
+	path := filepath.Join(dataDir, lockFileName)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		defer f.Close()
+		if !errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("failed to lock data directory: %w", err)
+		}
+		if pid := readLockPID(f); pid > 0 {
+			return nil, fmt.Errorf("another conduit is using data directory %s (pid %d)", dataDir, pid)
+		}
+		return nil, fmt.Errorf("another conduit is using data directory %s", dataDir)
+	}
+
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return &dirLock{f: f}, nil
+}
+
+// release clears the recorded pid and drops the lock
+func (l *dirLock) release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	l.f.Truncate(0)
+	unlockFile(l.f)
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// readLockPID returns the pid recorded in a lock file, or 0 if unknown
+func readLockPID(f *os.File) int {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// DataDirOwner reports whether another process holds the data directory
+// lock, i.e. a conduit is running with that data directory, and its pid if
+// known.
+func DataDirOwner(dataDir string) (pid int, running bool) {
+	f, err := os.OpenFile(filepath.Join(dataDir, lockFileName), os.O_RDWR, 0)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		if errors.Is(err, errLocked) {
+			return readLockPID(f), true
+		}
+		return 0, false
+	}
+	unlockFile(f)
+	return 0, false
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDataDirOwner(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, running := DataDirOwner(dir); running {
+		t.Fatal("empty data dir reported as in use")
+	}
+
+	lock, err := lockDataDir(dir)
+	if err != nil {
+		t.Fatalf("lockDataDir: %v", err)
+	}
+	pid, running := DataDirOwner(dir)
+	if !running || pid != os.Getpid() {
+		t.Fatalf("got pid %d running %t, expected pid %d running", pid, running, os.Getpid())
+	}
+
+	if err := lock.release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, running := DataDirOwner(dir); running {
+		t.Fatal("released data dir reported as in use")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PIDFile is a file holding this process's pid for external scripts and supervisors
+type PIDFile struct {
+	path string
+}
+
+// CreatePIDFile writes this process's pid to path. It refuses if the file
+// names another process that is still running, and replaces a stale file
+// left by one that has exited. The file is replaced atomically.
+func CreatePIDFile(path string) (*PIDFile, error) {
+	if pid := readPIDFile(path); pid > 0 && pid != os.Getpid() && processAlive(pid) {
+		return nil, fmt.Errorf("pid file %s belongs to running process %d", path, pid)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pid file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write pid file: %w", err)
+	}
+
+	return &PIDFile{path: path}, nil
+}
+
+// Remove deletes the pid file, unless another process has since replaced it
+func (p *PIDFile) Remove() error {
+	if p == nil || readPIDFile(p.path) != os.Getpid() {
+		return nil
+	}
+	if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove pid file: %w", err)
+	}
+	return nil
+}
+
+// readPIDFile returns the pid recorded at path, or 0 if there is none
+func readPIDFile(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
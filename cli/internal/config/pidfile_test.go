@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conduit.pid")
+
+	// A pid that can't belong to a running process is treated as stale
+	if err := os.WriteFile(path, []byte("999999999\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	pidFile, err := CreatePIDFile(path)
+	if err != nil {
+		t.Fatalf("CreatePIDFile over stale file: %v", err)
+	}
+	if pid := readPIDFile(path); pid != os.Getpid() {
+		t.Fatalf("pid file holds %d, expected %d", pid, os.Getpid())
+	}
+
+	if err := pidFile.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("pid file not removed: %v", err)
+	}
+}
+
+func TestPIDFileRunningProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conduit.pid")
+	parent := os.Getppid()
+	if parent <= 1 || !processAlive(parent) {
+		t.Skip("no live parent process to test against")
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(parent)), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := CreatePIDFile(path); err == nil || !strings.Contains(err.Error(), "running process") {
+		t.Fatalf("expected refusal for a live pid, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Auto-tuning ratios
+const (
+	clientsPerCPU   = 25
+	bytesPerClient  = 16 * 1024 * 1024
+	minTunedClients = 10
+)
+
+// Resources describes the host as seen by the auto-tuner. Zero values mean
+// the resource couldn't be measured.
+type Resources struct {
+	CPUs        int
+	MemoryBytes uint64
+}
+
+// DetectResources measures CPU count and total RAM where the platform
+// allows it
+func DetectResources() Resources {
+	return Resources{
+		CPUs:        runtime.NumCPU(),
+		MemoryBytes: totalMemoryBytes(),
+	}
+}
+
+// TunedMaxClients derives a max-clients default: clients scale with CPUs
+// and are capped by RAM. Bandwidth isn't tuned, as the limit applies to
+// each client rather than to the host.
+func (r Resources) TunedMaxClients() int {
+	clients := DefaultMaxClients
+	if r.CPUs > 0 {
+		clients = r.CPUs * clientsPerCPU
+	}
+	if r.MemoryBytes > 0 {
+		if memClients := int(r.MemoryBytes / bytesPerClient); memClients < clients {
+			clients = memClients
+		}
+	}
+	return max(minTunedClients, min(clients, MaxClientsLimit))
+}
+
+// String summarizes the measured resources, e.g. "4 CPUs, 3.8 GB RAM"
+func (r Resources) String() string {
+	parts := []string{fmt.Sprintf("%d CPUs", r.CPUs)}
+	if r.MemoryBytes > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f GB RAM", float64(r.MemoryBytes)/(1<<30)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,41 @@
+//go:build darwin || freebsd
+
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package config
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// totalMemoryBytes returns total RAM, or 0 if unknown
+func totalMemoryBytes() uint64 {
+	name := "hw.physmem"
+	if runtime.GOOS == "darwin" {
+		name = "hw.memsize"
+	}
+	mem, err := unix.SysctlUint64(name)
+	if err != nil {
+		return 0
+	}
+	return mem
+}
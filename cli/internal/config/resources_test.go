@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestTunedMaxClients(t *testing.T) {
+	const gb = 1 << 30
+	tests := []struct {
+		name            string
+		resources       Resources
+		expectedClients int
+	}{
+		{"unknown", Resources{}, DefaultMaxClients},
+		{"small_board", Resources{CPUs: 4, MemoryBytes: 1 * gb}, 64},
+		{"cpu_bound", Resources{CPUs: 2, MemoryBytes: 8 * gb}, 50},
+		{"large_vps", Resources{CPUs: 32, MemoryBytes: 64 * gb}, 800},
+		{"tiny", Resources{CPUs: 1, MemoryBytes: 64 * 1024 * 1024}, minTunedClients},
+		{"capped", Resources{CPUs: 128, MemoryBytes: 512 * gb}, MaxClientsLimit},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if clients := test.resources.TunedMaxClients(); clients != test.expectedClients {
+				t.Fatalf("clients = %d, expected %d", clients, test.expectedClients)
+			}
+		})
+	}
+}
@@ -25,37 +25,56 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
 const (
 	// MaxMind GeoLite2 Free Database (no account required)
-	// This is a direct download link for the GeoLite2-Country database
-	geoLite2URL = "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-Country.mmdb"
-
-	maxDownloadSize = 10 * 1024 * 1024 // 10MB max
-	downloadTimeout = 30 * time.Second
+	// These are direct download links for the GeoLite2 databases
+	geoLite2URL     = "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-Country.mmdb"
+	geoLite2CityURL = "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-City.mmdb"
+	geoLite2ASNURL  = "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-ASN.mmdb"
+
+	maxDownloadSize     = 10 * 1024 * 1024  // 10MB max
+	maxCityDownloadSize = 100 * 1024 * 1024 // City database is much larger
+	maxASNDownloadSize  = 20 * 1024 * 1024
+	downloadTimeout     = 30 * time.Second
+	cityDownloadTimeout = 5 * time.Minute
 )
 
-// EnsureDatabase checks if the GeoIP database exists, downloads if missing
-func EnsureDatabase(dbPath string) error {
+// databaseSource returns the download URL, size limit and timeout for a database path
+func databaseSource(dbPath string) (string, int64, time.Duration) {
+	switch strings.TrimSuffix(filepath.Base(dbPath), ".tmp") {
+	case CityDatabaseFile:
+		return geoLite2CityURL, maxCityDownloadSize, cityDownloadTimeout
+	case ASNDatabaseFile:
+		return geoLite2ASNURL, maxASNDownloadSize, downloadTimeout
+	}
+	return geoLite2URL, maxDownloadSize, downloadTimeout
+}
+
+// EnsureDatabase checks if the GeoIP database exists, downloads if missing.
+// Progress is reported to logf.
+func EnsureDatabase(dbPath string, logf func(format string, args ...any)) error {
 	// Check if database already exists
 	if _, err := os.Stat(dbPath); err == nil {
 		return nil
 	}
 
 	// Database doesn't exist, download it
-	fmt.Printf("[GEO] Downloading GeoLite2 database...\n")
-	return downloadDatabase(dbPath)
+	logf("[GEO] Downloading GeoLite2 database...\n")
+	return downloadDatabase(dbPath, logf)
 }
 
-// UpdateDatabase checks if database needs updating and downloads new version
-func UpdateDatabase(dbPath string) error {
+// UpdateDatabase checks if database needs updating and downloads new
+// version. Progress is reported to logf.
+func UpdateDatabase(dbPath string, logf func(format string, args ...any)) error {
 	// Check file modification time
 	info, err := os.Stat(dbPath)
 	if err != nil {
 		// Database doesn't exist, download it
-		return downloadDatabase(dbPath)
+		return downloadDatabase(dbPath, logf)
 	}
 
 	// Only update if older than 7 days
@@ -63,11 +82,11 @@ func UpdateDatabase(dbPath string) error {
 		return nil
 	}
 
-	fmt.Printf("[GEO] Updating GeoLite2 database...\n")
+	logf("[GEO] Updating GeoLite2 database...\n")
 
 	// Download to temporary file first
 	tmpPath := dbPath + ".tmp"
-	if err := downloadDatabase(tmpPath); err != nil {
+	if err := downloadDatabase(tmpPath, logf); err != nil {
 		return err
 	}
 
@@ -81,7 +100,9 @@ func UpdateDatabase(dbPath string) error {
 }
 
 // downloadDatabase downloads the GeoLite2 database
-func downloadDatabase(destPath string) error {
+func downloadDatabase(destPath string, logf func(format string, args ...any)) error {
+	url, limit, timeout := databaseSource(destPath)
+
 	// Ensure directory exists
 	dir := filepath.Dir(destPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -90,11 +111,11 @@ func downloadDatabase(destPath string) error {
 
 	// Create HTTP client with timeout
 	client := &http.Client{
-		Timeout: downloadTimeout,
+		Timeout: timeout,
 	}
 
 	// Download the database
-	resp, err := client.Get(geoLite2URL)
+	resp, err := client.Get(url)
 	if err != nil {
 		return fmt.Errorf("failed to download database: %w", err)
 	}
@@ -112,12 +133,12 @@ func downloadDatabase(destPath string) error {
 	defer out.Close()
 
 	// Copy with size limit
-	written, err := io.Copy(out, io.LimitReader(resp.Body, maxDownloadSize))
+	written, err := io.Copy(out, io.LimitReader(resp.Body, limit))
 	if err != nil {
 		os.Remove(destPath)
 		return fmt.Errorf("failed to write database: %w", err)
 	}
 
-	fmt.Printf("[GEO] Downloaded %d bytes\n", written)
+	logf("[GEO] Downloaded %d bytes\n", written)
 	return nil
 }
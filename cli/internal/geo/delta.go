@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import "sort"
+
+// Snapshot is a point-in-time copy of geo results keyed by country code
+type Snapshot map[string]Result
+
+// NewSnapshot builds a Snapshot from a list of results
+func NewSnapshot(results []Result) Snapshot {
+	snap := make(Snapshot, len(results))
+	for _, r := range results {
+		snap[r.Code] = r
+	}
+	return snap
+}
+
+// Snapshot returns the current results as a Snapshot
+func (c *Collector) Snapshot() Snapshot {
+	return NewSnapshot(c.GetResults())
+}
+
+// Delta returns the change in results since prev. Counts and bytes in the
+// returned results are signed differences; countries that disappeared are
+// included with negative counts and unchanged countries are omitted.
+func (c *Collector) Delta(prev Snapshot) []Result {
+	return Delta(prev, c.Snapshot())
+}
+
+// Delta returns the signed per-country differences between two snapshots,
+// ordered by the largest change in live clients first
+func Delta(prev, cur Snapshot) []Result {
+	var deltas []Result
+	for code, r := range cur {
+		p := prev[code]
+		d := Result{
+			Code:       code,
+			Country:    r.Country,
+			Count:      r.Count - p.Count,
+			CountTotal: r.CountTotal - p.CountTotal,
+			BytesUp:    r.BytesUp - p.BytesUp,
+			BytesDown:  r.BytesDown - p.BytesDown,
+		}
+		if d != (Result{Code: code, Country: r.Country}) {
+			deltas = append(deltas, d)
+		}
+	}
+	for code, p := range prev {
+		if _, ok := cur[code]; ok {
+			continue
+		}
+		deltas = append(deltas, Result{
+			Code:    code,
+			Country: p.Country,
+			Count:   -p.Count,
+		})
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		ai, aj := abs(deltas[i].Count), abs(deltas[j].Count)
+		if ai != aj {
+			return ai > aj
+		}
+		return deltas[i].Code < deltas[j].Code
+	})
+
+	return deltas
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import "testing"
+
+func TestDelta(t *testing.T) {
+	prev := NewSnapshot([]Result{
+		{Code: "IR", Country: "Iran", Count: 3, CountTotal: 10},
+		{Code: "CN", Country: "China", Count: 2, CountTotal: 5},
+		{Code: "RU", Country: "Russia", Count: 1, CountTotal: 1},
+	})
+	cur := NewSnapshot([]Result{
+		{Code: "IR", Country: "Iran", Count: 7, CountTotal: 15},
+		{Code: "CN", Country: "China", Count: 2, CountTotal: 5},
+		{Code: "MM", Country: "Myanmar", Count: 1, CountTotal: 1},
+	})
+
+	deltas := Delta(prev, cur)
+
+	expected := []Result{
+		{Code: "IR", Country: "Iran", Count: 4, CountTotal: 5},
+		{Code: "MM", Country: "Myanmar", Count: 1, CountTotal: 1},
+		{Code: "RU", Country: "Russia", Count: -1},
+	}
+	if len(deltas) != len(expected) {
+		t.Fatalf("got %d deltas, expected %d: %+v", len(deltas), len(expected), deltas)
+	}
+	for i := range expected {
+		if deltas[i] != expected[i] {
+			t.Fatalf("delta[%d] = %+v, expected %+v", i, deltas[i], expected[i])
+		}
+	}
+}
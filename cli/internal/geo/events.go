@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import "time"
+
+// subscriberBuffer is how far a subscriber can fall behind before its
+// events are dropped
+const subscriberBuffer = 64
+
+// EventKind says why an Event was sent
+type EventKind string
+
+const (
+	// EventNewCountry is sent for the first client from a country since
+	// start (or Reset), counting totals restored from a checkpoint
+	EventNewCountry EventKind = "new_country"
+
+	// EventThreshold is sent when a country's live clients rise to the
+	// threshold set with WithLiveThreshold
+	EventThreshold EventKind = "threshold"
+)
+
+// Event is a notable change in a country's stats
+type Event struct {
+	Kind EventKind `json:"kind"`
+	Time time.Time `json:"time"`
+	Result
+}
+
+// WithLiveThreshold sends an EventThreshold to subscribers each time a
+// country's live clients rise to n. Zero disables threshold events.
+func WithLiveThreshold(n int) Option {
+	return func(c *Collector) {
+		c.liveThreshold = n
+	}
+}
+
+// Subscribe returns a channel of country events. Events are sent without
+// blocking: if the subscriber falls more than a few dozen events behind,
+// new events are dropped rather than stalling connection tracking. The
+// channel is closed by Unsubscribe or Stop.
+func (c *Collector) Subscribe() <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		close(ch)
+		return ch
+	}
+	c.subscribers = append(c.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops events to a channel from Subscribe and closes it
+func (c *Collector) Unsubscribe(sub <-chan Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, ch := range c.subscribers {
+		if ch == sub {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// publish sends an event for a country to every subscriber, dropping it for
+// those that are full. Must be called with lock held.
+func (c *Collector) publish(kind EventKind, code string, cd *countryData) {
+	if len(c.subscribers) == 0 {
+		return
+	}
+	event := Event{
+		Kind: kind,
+		Time: time.Now().UTC(),
+		Result: Result{
+			Code:       code,
+			Country:    cd.name,
+			Count:      cd.live,
+			CountTotal: cd.uniqueIPs(),
+			BytesUp:    cd.bytesUp,
+			BytesDown:  cd.bytesDown,
+		},
+	}
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// closeSubscribers closes every subscriber channel. Must be called with lock held.
+func (c *Collector) closeSubscribers() {
+	for _, ch := range c.subscribers {
+		close(ch)
+	}
+	c.subscribers = nil
+	c.stopped = true
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import "testing"
+
+func TestSubscribe(t *testing.T) {
+	c := NewCollector("")
+	events := c.Subscribe()
+	other := c.Subscribe()
+
+	ir := c.newCountryData("Iran")
+	ir.live = 1
+	c.mu.Lock()
+	c.publish(EventNewCountry, "IR", ir)
+	c.mu.Unlock()
+
+	event := <-events
+	if event.Kind != EventNewCountry || event.Code != "IR" || event.Country != "Iran" || event.Count != 1 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	// A slow subscriber loses events instead of blocking the collector
+	c.mu.Lock()
+	for i := 0; i < subscriberBuffer+10; i++ {
+		c.publish(EventThreshold, "IR", ir)
+	}
+	c.mu.Unlock()
+	if len(events) != subscriberBuffer {
+		t.Fatalf("expected a full buffer of %d events, got %d", subscriberBuffer, len(events))
+	}
+
+	c.Unsubscribe(other)
+	for range other {
+	}
+
+	c.Stop()
+	for range events {
+	}
+	if _, ok := <-c.Subscribe(); ok {
+		t.Fatal("Subscribe after Stop should return a closed channel")
+	}
+}
@@ -24,56 +24,241 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/axiomhq/hyperloglog"
 	"github.com/oschwald/geoip2-golang"
 )
 
-// Result represents a country with connection stats
+// GeoLite2 database file names in the data directory
+const (
+	CountryDatabaseFile = "GeoLite2-Country.mmdb"
+	CityDatabaseFile    = "GeoLite2-City.mmdb"
+	ASNDatabaseFile     = "GeoLite2-ASN.mmdb"
+)
+
+// Result represents a country with connection stats. ASN and Org are set
+// only by LookupASNs, when the ASN database is open.
 type Result struct {
 	Code       string `json:"code"`
 	Country    string `json:"country"`
+	ASN        uint   `json:"asn,omitempty"`
+	Org        string `json:"org,omitempty"`
 	Count      int    `json:"count"`       // Currently connected clients
 	CountTotal int    `json:"count_total"` // Total unique clients since start
 	BytesUp    int64  `json:"bytes_up"`    // Total bytes since start
 	BytesDown  int64  `json:"bytes_down"`  // Total bytes since start
 }
 
+// CityResult represents a city with connection stats (city tracking only)
+type CityResult struct {
+	Code        string `json:"code"`
+	Country     string `json:"country"`
+	Subdivision string `json:"subdivision,omitempty"` // First-level region, e.g. a state or province
+	City        string `json:"city"`
+	Count       int    `json:"count"`
+	CountTotal  int    `json:"count_total"`
+	BytesUp     int64  `json:"bytes_up"`
+	BytesDown   int64  `json:"bytes_down"`
+}
+
+// ASNResult represents a network (autonomous system) with connection stats (ASN tracking only)
+type ASNResult struct {
+	ASN        uint   `json:"asn"`
+	Org        string `json:"org"`
+	Count      int    `json:"count"`
+	CountTotal int    `json:"count_total"`
+	BytesUp    int64  `json:"bytes_up"`
+	BytesDown  int64  `json:"bytes_down"`
+}
+
+// UnknownCity is the city name for IPs the city database can't place
+const UnknownCity = "Unknown"
+
+// cityKey identifies a city within a country
+type cityKey struct {
+	code        string
+	subdivision string
+	city        string
+}
+
 // countryData stores stats per country
 type countryData struct {
-	name      string
-	live      int                 // currently open connections
-	totalIPs  map[string]struct{} // all unique IPs ever seen
-	bytesUp   int64
-	bytesDown int64
+	name        string
+	live        int                  // currently open connections
+	totalIPs    map[string]time.Time // unique IPs seen -> last seen (exact mode)
+	totalSketch *hyperloglog.Sketch  // estimated unique IPs (estimate mode)
+	window      time.Duration        // only count IPs seen this recently (0 = since start)
+	bytesUp     int64
+	bytesDown   int64
+}
+
+// addIP records an IP as seen for this country
+func (cd *countryData) addIP(ipStr string) {
+	if cd.totalSketch != nil {
+		cd.totalSketch.Insert([]byte(ipStr))
+		return
+	}
+	cd.totalIPs[ipStr] = time.Now()
+}
+
+// uniqueIPs returns the (possibly estimated) number of unique IPs seen,
+// within the window if one is set
+func (cd *countryData) uniqueIPs() int {
+	if cd.totalSketch != nil {
+		return int(cd.totalSketch.Estimate())
+	}
+	if cd.window == 0 {
+		return len(cd.totalIPs)
+	}
+	count := 0
+	cutoff := time.Now().Add(-cd.window)
+	for _, seen := range cd.totalIPs {
+		if seen.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// prune forgets IPs last seen before the window
+func (cd *countryData) prune(cutoff time.Time) {
+	for ip, seen := range cd.totalIPs {
+		if !seen.After(cutoff) {
+			delete(cd.totalIPs, ip)
+		}
+	}
+}
+
+// clearHistory forgets seen IPs and bytes, keeping live connection counts
+func (cd *countryData) clearHistory() {
+	if cd.totalSketch != nil {
+		cd.totalSketch = hyperloglog.New()
+	} else {
+		cd.totalIPs = make(map[string]time.Time)
+	}
+	cd.bytesUp = 0
+	cd.bytesDown = 0
 }
 
 // Collector collects geo stats
 type Collector struct {
-	mu        sync.RWMutex
-	countries map[string]*countryData // country code -> data
-	relayLive int                     // currently open relay connections
-	relayAll  map[string]struct{}     // all unique relay IPs ever seen
-	relayUp   int64
-	relayDown int64
-	db        *geoip2.Reader
-	dbPath    string
+	mu             sync.RWMutex
+	countries      map[string]*countryData // country code -> data
+	relay          *countryData            // TURN relay connections (no location)
+	uniqueClients  *hyperloglog.Sketch     // all client IPs ever seen, relay included
+	estimateUnique bool
+	window         time.Duration
+	db             *geoip2.Reader
+	dbPath         string
+	cities         map[cityKey]*countryData // name holds the country name
+	cityDB         *geoip2.Reader
+	cityPath       string                // empty = city tracking disabled
+	asns           map[uint]*countryData // name holds the organization
+	asnDB          *geoip2.Reader
+	asnPath        string // empty = ASN tracking disabled
+	observations   *ObservationLog
+	logf           func(format string, args ...any) // progress and problems, see WithLogger
+
+	since              time.Time         // when totals started accumulating
+	restored           map[string]Result // totals from before a restart, by code
+	checkpointPath     string            // empty = no checkpoints
+	checkpointInterval time.Duration
+
+	rateWindow time.Duration        // period GetConnectionRate averages over
+	rateSeen   map[string]time.Time // IP -> when it last counted as new
+
+	subscribers   []chan Event // from Subscribe
+	liveThreshold int          // live clients per country that triggers EventThreshold (0 = off)
+	stopped       bool         // Stop was called; no new subscribers
+}
+
+// Option configures optional Collector behavior
+type Option func(*Collector)
+
+// WithUniqueEstimation makes the Collector estimate per-country unique
+// clients with HyperLogLog sketches instead of keeping every IP in memory.
+// Counts are approximate (~1% error) but memory stays bounded on busy relays.
+func WithUniqueEstimation() Option {
+	return func(c *Collector) {
+		c.estimateUnique = true
+	}
+}
+
+// WithWindow makes count_total a sliding window: the unique clients seen in
+// the last d rather than since start. A client is counted once per window no
+// matter how often it reconnects. Ignored with WithUniqueEstimation, whose
+// sketches can't forget entries. Bytes still accumulate since start.
+func WithWindow(d time.Duration) Option {
+	return func(c *Collector) {
+		c.window = d
+	}
+}
+
+// WithLogger sends the Collector's download, checkpoint and warning
+// messages to logf instead of stdout
+func WithLogger(logf func(format string, args ...any)) Option {
+	return func(c *Collector) {
+		c.logf = logf
+	}
+}
+
+// WithCityDatabase enables per-city tracking from the GeoLite2-City
+// database at path, in addition to countries. The database is much larger
+// and city-level data is more identifying, so this is opt-in.
+func WithCityDatabase(path string) Option {
+	return func(c *Collector) {
+		c.cityPath = path
+		c.cities = make(map[cityKey]*countryData)
+	}
+}
+
+// WithASNDatabase enables per-network tracking from the GeoLite2-ASN
+// database at path. If the database can't be downloaded or opened, the
+// Collector runs without ASN data rather than failing.
+func WithASNDatabase(path string) Option {
+	return func(c *Collector) {
+		c.asnPath = path
+		c.asns = make(map[uint]*countryData)
+	}
 }
 
 // NewCollector creates a new geo stats collector
-func NewCollector(dbPath string) *Collector {
-	return &Collector{
-		dbPath:    dbPath,
-		countries: make(map[string]*countryData),
-		relayAll:  make(map[string]struct{}),
+func NewCollector(dbPath string, opts ...Option) *Collector {
+	c := &Collector{
+		dbPath:        dbPath,
+		countries:     make(map[string]*countryData),
+		uniqueClients: hyperloglog.New(),
+		since:         time.Now().UTC(),
+		rateWindow:    DefaultRateWindow,
+		rateSeen:      make(map[string]time.Time),
+		logf:          func(format string, args ...any) { fmt.Printf(format, args...) },
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.relay = c.newCountryData("Unknown (TURN Relay)")
+	return c
 }
 
-// Start begins collecting geo stats in the background
-func (c *Collector) Start(ctx context.Context) error {
-	if err := EnsureDatabase(c.dbPath); err != nil {
+// newCountryData creates per-country stats for the Collector's counting mode
+func (c *Collector) newCountryData(name string) *countryData {
+	cd := &countryData{name: name}
+	if c.estimateUnique {
+		cd.totalSketch = hyperloglog.New()
+	} else {
+		cd.totalIPs = make(map[string]time.Time)
+		cd.window = c.window
+	}
+	return cd
+}
+
+// Open ensures the GeoIP database exists (downloading it if missing) and opens it
+func (c *Collector) Open() error {
+	if err := EnsureDatabase(c.dbPath, c.logf); err != nil {
 		return fmt.Errorf("failed to ensure database: %w", err)
 	}
 
@@ -81,19 +266,90 @@ func (c *Collector) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to open GeoIP database: %w", err)
 	}
+
+	var cityDB *geoip2.Reader
+	if c.cityPath != "" {
+		if err := EnsureDatabase(c.cityPath, c.logf); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to ensure city database: %w", err)
+		}
+		cityDB, err = geoip2.Open(c.cityPath)
+		if err != nil {
+			db.Close()
+			return fmt.Errorf("failed to open GeoIP city database: %w", err)
+		}
+	}
+
+	var asnDB *geoip2.Reader
+	if c.asnPath != "" {
+		asnDB, err = openOptional(c.asnPath, c.logf)
+		if err != nil {
+			c.logf("[WARN] ASN data unavailable: %v\n", err)
+		}
+	}
+
+	c.mu.Lock()
 	c.db = db
+	c.cityDB = cityDB
+	c.asnDB = asnDB
+	c.mu.Unlock()
+
+	return nil
+}
+
+// openOptional downloads if needed and opens a database the Collector can run without
+func openOptional(path string, logf func(format string, args ...any)) (*geoip2.Reader, error) {
+	if err := EnsureDatabase(path, logf); err != nil {
+		return nil, err
+	}
+	return geoip2.Open(path)
+}
+
+// Start opens the database and begins collecting geo stats in the background
+func (c *Collector) Start(ctx context.Context) error {
+	if err := c.Open(); err != nil {
+		return err
+	}
+
+	if c.checkpointPath != "" {
+		c.restoreCheckpoint()
+		go c.checkpoint(ctx)
+	}
 
 	go c.autoUpdate(ctx)
+	go c.pruneRate(ctx)
+	if c.window > 0 && !c.estimateUnique {
+		go c.pruneWindow(ctx)
+	}
 
 	return nil
 }
 
-// Stop closes the database
+// Stop saves a final checkpoint if enabled, closes subscriber channels and
+// closes the database
 func (c *Collector) Stop() error {
+	if c.checkpointPath != "" {
+		if err := c.SaveResults(c.checkpointPath); err != nil {
+			c.logf("[WARN] %v\n", err)
+		}
+	}
+
+	// Readers are cleared so callbacks arriving after Stop skip the lookup
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.closeSubscribers()
+	if c.cityDB != nil {
+		c.cityDB.Close()
+		c.cityDB = nil
+	}
+	if c.asnDB != nil {
+		c.asnDB.Close()
+		c.asnDB = nil
+	}
 	if c.db != nil {
-		return c.db.Close()
+		err := c.db.Close()
+		c.db = nil
+		return err
 	}
 	return nil
 }
@@ -104,35 +360,45 @@ func (c *Collector) ConnectIP(ipStr string) {
 	if ip == nil || isPrivateIP(ip) {
 		return
 	}
+	// IPv6 has many spellings of one address; count the canonical form
+	ipStr = ip.String()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.db == nil {
-		return
-	}
+	c.uniqueClients.Insert([]byte(ipStr))
+	c.noteNewIP(ipStr, time.Now())
 
-	record, err := c.db.Country(ip)
-	if err != nil || record.Country.IsoCode == "" {
+	code, name, ok := c.lookupCountry(ip)
+	if !ok {
 		return
 	}
 
-	code := record.Country.IsoCode
 	cd, exists := c.countries[code]
 	if !exists {
-		name := code
-		if countryName, ok := record.Country.Names["en"]; ok && countryName != "" {
-			name = countryName
-		}
-		cd = &countryData{
-			name:     name,
-			totalIPs: make(map[string]struct{}),
-		}
+		cd = c.newCountryData(name)
 		c.countries[code] = cd
 	}
 
 	cd.live++
-	cd.totalIPs[ipStr] = struct{}{}
+	cd.addIP(ipStr)
+	c.observations.observe(ipStr, code, name)
+
+	if _, restored := c.restored[code]; !exists && !restored {
+		c.publish(EventNewCountry, code, cd)
+	}
+	if c.liveThreshold > 0 && cd.live == c.liveThreshold {
+		c.publish(EventThreshold, code, cd)
+	}
+
+	if city := c.lookupCity(ip, code, name); city != nil {
+		city.live++
+		city.addIP(ipStr)
+	}
+	if network := c.lookupASN(ip); network != nil {
+		network.live++
+		network.addIP(ipStr)
+	}
 }
 
 // DisconnectIP records bandwidth and closes connection (call when connection closes)
@@ -141,60 +407,241 @@ func (c *Collector) DisconnectIP(ipStr string, bytesUp, bytesDown int64) {
 	if ip == nil || isPrivateIP(ip) {
 		return
 	}
+	// IPv6 has many spellings of one address; count the canonical form
+	ipStr = ip.String()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.db == nil {
-		return
-	}
-
-	record, err := c.db.Country(ip)
-	if err != nil || record.Country.IsoCode == "" {
+	code, name, ok := c.lookupCountry(ip)
+	if !ok {
 		return
 	}
 
-	code := record.Country.IsoCode
 	cd, exists := c.countries[code]
 	if !exists {
 		// Shouldn't happen, but handle gracefully
-		name := code
-		if countryName, ok := record.Country.Names["en"]; ok && countryName != "" {
-			name = countryName
-		}
-		cd = &countryData{
-			name:     name,
-			totalIPs: make(map[string]struct{}),
-		}
+		cd = c.newCountryData(name)
 		c.countries[code] = cd
 	}
 
 	if cd.live > 0 {
 		cd.live--
 	}
-	cd.totalIPs[ipStr] = struct{}{}
+	cd.addIP(ipStr)
 	cd.bytesUp += bytesUp
 	cd.bytesDown += bytesDown
+
+	if city := c.lookupCity(ip, code, name); city != nil {
+		if city.live > 0 {
+			city.live--
+		}
+		city.addIP(ipStr)
+		city.bytesUp += bytesUp
+		city.bytesDown += bytesDown
+	}
+	if network := c.lookupASN(ip); network != nil {
+		if network.live > 0 {
+			network.live--
+		}
+		network.addIP(ipStr)
+		network.bytesUp += bytesUp
+		network.bytesDown += bytesDown
+	}
+}
+
+// lookupCountry resolves an IP to a country code and English name. Must be called with lock held.
+func (c *Collector) lookupCountry(ip net.IP) (code, name string, ok bool) {
+	if c.db == nil {
+		return "", "", false
+	}
+
+	record, err := c.db.Country(ip)
+	if err != nil || record.Country.IsoCode == "" {
+		return "", "", false
+	}
+
+	code = record.Country.IsoCode
+	name = code
+	if countryName, ok := record.Country.Names["en"]; ok && countryName != "" {
+		name = countryName
+	}
+	return code, name, true
+}
+
+// lookupCity returns the stats entry for an IP's city, creating it if needed,
+// or nil if city tracking is off. IPs the database can't place in a city go to
+// an UnknownCity entry for their country so city totals match country totals.
+// Must be called with lock held.
+func (c *Collector) lookupCity(ip net.IP, code, countryName string) *countryData {
+	if c.cityDB == nil {
+		return nil
+	}
+
+	key := cityKey{code: code, city: UnknownCity}
+	if record, err := c.cityDB.City(ip); err == nil {
+		if name := record.City.Names["en"]; name != "" {
+			key.city = name
+		}
+		if len(record.Subdivisions) > 0 {
+			key.subdivision = record.Subdivisions[0].Names["en"]
+		}
+	}
+
+	cd, exists := c.cities[key]
+	if !exists {
+		cd = c.newCountryData(countryName)
+		c.cities[key] = cd
+	}
+	return cd
+}
+
+// lookupASN returns the stats entry for an IP's network, creating it if needed,
+// or nil if ASN tracking is off or the network is unknown. Must be called with lock held.
+func (c *Collector) lookupASN(ip net.IP) *countryData {
+	if c.asnDB == nil {
+		return nil
+	}
+
+	asn, org := c.lookupNetwork(ip)
+	if asn == 0 {
+		return nil
+	}
+
+	cd, exists := c.asns[asn]
+	if !exists {
+		cd = c.newCountryData(org)
+		c.asns[asn] = cd
+	}
+	return cd
+}
+
+// lookupNetwork returns an IP's autonomous system number and organization,
+// or 0 and "" if the ASN database isn't open or doesn't know the IP. Must
+// be called with lock held.
+func (c *Collector) lookupNetwork(ip net.IP) (uint, string) {
+	if c.asnDB == nil {
+		return 0, ""
+	}
+	record, err := c.asnDB.ASN(ip)
+	if err != nil {
+		return 0, ""
+	}
+	return record.AutonomousSystemNumber, record.AutonomousSystemOrganization
+}
+
+// Location is how a single IP would be classified by the Collector
+type Location struct {
+	IP      string `json:"ip"`
+	Private bool   `json:"private"` // ignored by the Collector
+	Code    string `json:"code,omitempty"`
+	Country string `json:"country,omitempty"`
+	ASN     uint   `json:"asn,omitempty"` // Only with the ASN database
+	Org     string `json:"org,omitempty"`
+}
+
+// Lookup resolves one IP through the same filtering and database lookup
+// used for live connections, without recording anything
+func (c *Collector) Lookup(ipStr string) (Location, error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return Location{}, fmt.Errorf("invalid IP address: %q", ipStr)
+	}
+
+	loc := Location{IP: ip.String(), Private: isPrivateIP(ip)}
+	if loc.Private {
+		return loc, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.db == nil {
+		return loc, fmt.Errorf("GeoIP database is not open")
+	}
+	loc.Code, loc.Country, _ = c.lookupCountry(ip)
+	loc.ASN, loc.Org = c.lookupNetwork(ip)
+	return loc, nil
+}
+
+// LookupASNs resolves IPs like Lookup and groups them by country and
+// network, most IPs first. Count is the number of IPs in each group.
+// Without the ASN database, ASN and Org are empty and IPs are grouped by
+// country only. Private IPs are skipped, as they are for live connections.
+func (c *Collector) LookupASNs(ipStrs []string) ([]Result, error) {
+	type groupKey struct {
+		code string
+		asn  uint
+	}
+	groups := make(map[groupKey]*Result)
+	for _, ipStr := range ipStrs {
+		loc, err := c.Lookup(ipStr)
+		if err != nil {
+			return nil, err
+		}
+		if loc.Private {
+			continue
+		}
+		key := groupKey{loc.Code, loc.ASN}
+		r, exists := groups[key]
+		if !exists {
+			r = &Result{Code: loc.Code, Country: loc.Country, ASN: loc.ASN, Org: loc.Org}
+			groups[key] = r
+		}
+		r.Count++
+	}
+
+	results := make([]Result, 0, len(groups))
+	for _, r := range groups {
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		if results[i].Code != results[j].Code {
+			return results[i].Code < results[j].Code
+		}
+		return results[i].ASN < results[j].ASN
+	})
+	return results, nil
 }
 
 // ConnectRelay records a new relay connection (call when connection opens)
 func (c *Collector) ConnectRelay(ipStr string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.relayLive++
-	c.relayAll[ipStr] = struct{}{}
+	c.uniqueClients.Insert([]byte(ipStr))
+	c.relay.live++
+	c.relay.addIP(ipStr)
+	c.observations.observe(ipStr, "RELAY", c.relay.name)
 }
 
 // DisconnectRelay records bandwidth and closes relay connection (call when connection closes)
 func (c *Collector) DisconnectRelay(ipStr string, bytesUp, bytesDown int64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.relayLive > 0 {
-		c.relayLive--
+	if c.relay.live > 0 {
+		c.relay.live--
 	}
-	c.relayAll[ipStr] = struct{}{}
-	c.relayUp += bytesUp
-	c.relayDown += bytesDown
+	c.relay.addIP(ipStr)
+	c.relay.bytesUp += bytesUp
+	c.relay.bytesDown += bytesDown
+}
+
+// Since returns when the totals in GetResults started accumulating: the
+// start of this run, or of the first run restored from a checkpoint
+func (c *Collector) Since() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.since
+}
+
+// EstimatedUniqueClients returns the estimated number of distinct client IPs
+// seen since start, across all countries and relay connections
+func (c *Collector) EstimatedUniqueClients() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.uniqueClients.Estimate()
 }
 
 // autoUpdate checks for database updates once per day
@@ -207,22 +654,136 @@ func (c *Collector) autoUpdate(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := UpdateDatabase(c.dbPath); err != nil {
-				continue
+			if err := UpdateDatabase(c.dbPath, c.logf); err == nil {
+				c.reopen(&c.db, c.dbPath)
 			}
-			c.mu.Lock()
-			if c.db != nil {
-				c.db.Close()
+			if c.cityPath != "" {
+				if err := UpdateDatabase(c.cityPath, c.logf); err == nil {
+					c.reopen(&c.cityDB, c.cityPath)
+				}
 			}
-			db, err := geoip2.Open(c.dbPath)
-			if err == nil {
-				c.db = db
+			if c.asnPath != "" {
+				if err := UpdateDatabase(c.asnPath, c.logf); err == nil {
+					c.reopen(&c.asnDB, c.asnPath)
+				}
 			}
+		}
+	}
+}
+
+// pruneWindow periodically drops IPs that have left the window so memory
+// tracks recent clients rather than every client since start
+func (c *Collector) pruneWindow(ctx context.Context) {
+	ticker := time.NewTicker(max(c.window/10, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			c.forEachData(func(cd *countryData) { cd.prune(now.Add(-c.window)) })
 			c.mu.Unlock()
 		}
 	}
 }
 
+// forEachData calls fn for every stats entry. Must be called with lock held.
+func (c *Collector) forEachData(fn func(cd *countryData)) {
+	fn(c.relay)
+	for _, cd := range c.countries {
+		fn(cd)
+	}
+	for _, cd := range c.cities {
+		fn(cd)
+	}
+	for _, cd := range c.asns {
+		fn(cd)
+	}
+}
+
+// Reset clears accumulated history: unique client counts and bytes, totals
+// restored from a checkpoint, the connection rate, and entries with no open
+// connections. Live connection counts are kept so later disconnects still
+// balance.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.uniqueClients = hyperloglog.New()
+	c.since = time.Now().UTC()
+	c.restored = nil
+	c.rateSeen = make(map[string]time.Time)
+	c.forEachData(func(cd *countryData) { cd.clearHistory() })
+	for code, cd := range c.countries {
+		if cd.live == 0 {
+			delete(c.countries, code)
+		}
+	}
+	for key, cd := range c.cities {
+		if cd.live == 0 {
+			delete(c.cities, key)
+		}
+	}
+	for asn, cd := range c.asns {
+		if cd.live == 0 {
+			delete(c.asns, asn)
+		}
+	}
+}
+
+// reopen swaps *db for a freshly opened reader of path, keeping the old
+// reader if the new one can't be opened. Returns the new database's build time.
+func (c *Collector) reopen(db **geoip2.Reader, path string) (time.Time, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Taking the write lock waits for lookups still using the old reader
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if *db != nil {
+		(*db).Close()
+	}
+	*db = reader
+	return time.Unix(int64(reader.Metadata().BuildEpoch), 0), nil
+}
+
+// DatabaseInfo describes a database file reopened by ReloadDB
+type DatabaseInfo struct {
+	File  string    // base name, e.g. GeoLite2-Country.mmdb
+	Built time.Time // build time from the database metadata
+}
+
+// ReloadDB reopens the database files in place, e.g. after they were
+// replaced by an external updater, and returns what was loaded. On failure
+// the current reader is kept.
+func (c *Collector) ReloadDB() ([]DatabaseInfo, error) {
+	built, err := c.reopen(&c.db, c.dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload GeoIP database: %w", err)
+	}
+	loaded := []DatabaseInfo{{File: filepath.Base(c.dbPath), Built: built}}
+
+	if c.cityPath != "" {
+		built, err := c.reopen(&c.cityDB, c.cityPath)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to reload GeoIP city database: %w", err)
+		}
+		loaded = append(loaded, DatabaseInfo{File: filepath.Base(c.cityPath), Built: built})
+	}
+
+	if c.asnPath != "" {
+		built, err := c.reopen(&c.asnDB, c.asnPath)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to reload GeoIP ASN database: %w", err)
+		}
+		loaded = append(loaded, DatabaseInfo{File: filepath.Base(c.asnPath), Built: built})
+	}
+	return loaded, nil
+}
+
 // GetResults returns the current geo stats (includes relay as special entry)
 func (c *Collector) GetResults() []Result {
 	c.mu.RLock()
@@ -234,23 +795,24 @@ func (c *Collector) GetResults() []Result {
 			Code:       code,
 			Country:    cd.name,
 			Count:      cd.live,
-			CountTotal: len(cd.totalIPs),
+			CountTotal: cd.uniqueIPs(),
 			BytesUp:    cd.bytesUp,
 			BytesDown:  cd.bytesDown,
 		})
 	}
 
 	// Add relay stats as special entry if any relay connections occurred
-	if len(c.relayAll) > 0 || c.relayLive > 0 {
+	if relayTotal := c.relay.uniqueIPs(); relayTotal > 0 || c.relay.live > 0 {
 		results = append(results, Result{
 			Code:       "RELAY",
-			Country:    "Unknown (TURN Relay)",
-			Count:      c.relayLive,
-			CountTotal: len(c.relayAll),
-			BytesUp:    c.relayUp,
-			BytesDown:  c.relayDown,
+			Country:    c.relay.name,
+			Count:      c.relay.live,
+			CountTotal: relayTotal,
+			BytesUp:    c.relay.bytesUp,
+			BytesDown:  c.relay.bytesDown,
 		})
 	}
+	results = c.addRestored(results)
 
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Count > results[j].Count
@@ -259,7 +821,112 @@ func (c *Collector) GetResults() []Result {
 	return results
 }
 
-// isPrivateIP checks if an IP is private/internal
+// GetResultsByCity returns per-city stats, or nil if city tracking is off.
+// Relay connections have no location and are not included.
+func (c *Collector) GetResultsByCity() []CityResult {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cities == nil {
+		return nil
+	}
+
+	results := make([]CityResult, 0, len(c.cities))
+	for key, cd := range c.cities {
+		results = append(results, CityResult{
+			Code:        key.code,
+			Country:     cd.name,
+			Subdivision: key.subdivision,
+			City:        key.city,
+			Count:       cd.live,
+			CountTotal:  cd.uniqueIPs(),
+			BytesUp:     cd.bytesUp,
+			BytesDown:   cd.bytesDown,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Count > results[j].Count
+	})
+
+	return results
+}
+
+// GetASNResults returns per-network stats, or nil if ASN tracking is off.
+// Without an ASN database the result is empty. Relay connections are not included.
+func (c *Collector) GetASNResults() []ASNResult {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.asns == nil {
+		return nil
+	}
+
+	results := make([]ASNResult, 0, len(c.asns))
+	for asn, cd := range c.asns {
+		results = append(results, ASNResult{
+			ASN:        asn,
+			Org:        cd.name,
+			Count:      cd.live,
+			CountTotal: cd.uniqueIPs(),
+			BytesUp:    cd.bytesUp,
+			BytesDown:  cd.bytesDown,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Count > results[j].Count
+	})
+
+	return results
+}
+
+// reservedNets are ranges that can't be geolocated: private, shared (CGNAT),
+// loopback, link-local, documentation, multicast and reserved space
+var reservedNets = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// mustParseCIDRs parses a fixed list of CIDRs, panicking on a typo
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// isPrivateIP checks if an IP is private, internal or otherwise reserved.
+// IPv4-mapped IPv6 addresses are checked against the IPv4 ranges.
 func isPrivateIP(ip net.IP) bool {
-	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGetResultsByCity(t *testing.T) {
+	if results := NewCollector("").GetResultsByCity(); results != nil {
+		t.Fatalf("expected nil without city tracking, got %+v", results)
+	}
+
+	c := NewCollector("", WithCityDatabase(""))
+	tehran := c.newCountryData("Iran")
+	tehran.live = 1
+	tehran.addIP("203.0.113.1")
+	tehran.addIP("203.0.113.2")
+	c.cities[cityKey{code: "IR", subdivision: "Tehran", city: "Tehran"}] = tehran
+
+	mashhad := c.newCountryData("Iran")
+	mashhad.live = 3
+	mashhad.addIP("203.0.113.3")
+	c.cities[cityKey{code: "IR", subdivision: "Razavi Khorasan", city: "Mashhad"}] = mashhad
+
+	results := c.GetResultsByCity()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 cities, got %+v", results)
+	}
+	if results[0].City != "Mashhad" || results[0].Count != 3 || results[0].Subdivision != "Razavi Khorasan" {
+		t.Fatalf("expected Mashhad first by live count, got %+v", results[0])
+	}
+	if results[1].City != "Tehran" || results[1].CountTotal != 2 || results[1].Country != "Iran" {
+		t.Fatalf("unexpected Tehran result %+v", results[1])
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		private bool
+	}{
+		{"10.1.2.3", true},
+		{"172.31.255.1", true},
+		{"192.168.1.1", true},
+		{"127.0.0.1", true},
+		{"100.64.1.1", true},
+		{"169.254.10.10", true},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		{"0.0.0.0", true},
+		{"172.32.0.1", false},
+		{"100.128.0.1", false},
+		{"8.8.8.8", false},
+	}
+	for _, test := range tests {
+		if got := isPrivateIP(net.ParseIP(test.ip)); got != test.private {
+			t.Errorf("isPrivateIP(%s) = %v, expected %v", test.ip, got, test.private)
+		}
+	}
+}
+
+func TestIsPrivateIPv6(t *testing.T) {
+	tests := []struct {
+		ip      string
+		private bool
+	}{
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd12:3456:789a::1", true},
+		{"fc00::1", true},
+		{"ff02::1", true},
+		{"2001:db8::1", true},
+		{"::ffff:10.0.0.1", true},
+		{"2a00:1450:4001:80b::200e", false},
+		{"::ffff:8.8.8.8", false},
+	}
+	for _, test := range tests {
+		if got := isPrivateIP(net.ParseIP(test.ip)); got != test.private {
+			t.Errorf("isPrivateIP(%s) = %v, expected %v", test.ip, got, test.private)
+		}
+	}
+}
+
+func TestConnectIPCanonicalizesIPv6(t *testing.T) {
+	c := NewCollector("")
+	c.ConnectIP("2a00:1450:4001:80b::200e")
+	c.ConnectIP("2A00:1450:4001:080B:0:0:0:200E")
+	if unique := c.EstimatedUniqueClients(); unique != 1 {
+		t.Fatalf("expected one unique client for two spellings of an address, got %d", unique)
+	}
+}
+
+func TestGetASNResults(t *testing.T) {
+	if results := NewCollector("").GetASNResults(); results != nil {
+		t.Fatalf("expected nil without ASN tracking, got %+v", results)
+	}
+
+	c := NewCollector("", WithASNDatabase(""))
+	if results := c.GetASNResults(); len(results) != 0 {
+		t.Fatalf("expected no networks before any lookups, got %+v", results)
+	}
+	// Without a database, connections are still counted by country only
+	c.ConnectIP("8.8.8.8")
+	if results := c.GetASNResults(); len(results) != 0 {
+		t.Fatalf("expected no networks without a database, got %+v", results)
+	}
+
+	network := c.newCountryData("Example Telecom")
+	network.live = 2
+	network.addIP("8.8.8.8")
+	network.bytesDown = 1024
+	c.asns[64500] = network
+
+	results := c.GetASNResults()
+	if len(results) != 1 || results[0].ASN != 64500 || results[0].Org != "Example Telecom" ||
+		results[0].Count != 2 || results[0].CountTotal != 1 || results[0].BytesDown != 1024 {
+		t.Fatalf("unexpected ASN results %+v", results)
+	}
+}
+
+func TestWindowAndReset(t *testing.T) {
+	c := NewCollector("", WithWindow(time.Hour))
+	ir := c.newCountryData("Iran")
+	ir.live = 1
+	ir.addIP("203.0.113.1")
+	ir.addIP("203.0.113.1")
+	ir.totalIPs["203.0.113.2"] = time.Now().Add(-2 * time.Hour)
+	ir.bytesUp = 100
+	c.countries["IR"] = ir
+	c.countries["CN"] = c.newCountryData("China")
+	c.noteNewIP("203.0.113.1", time.Now())
+
+	if got := ir.uniqueIPs(); got != 1 {
+		t.Fatalf("expected 1 client inside the window, got %d", got)
+	}
+	ir.prune(time.Now().Add(-time.Hour))
+	if len(ir.totalIPs) != 1 {
+		t.Fatalf("prune kept stale IPs: %v", ir.totalIPs)
+	}
+
+	c.Reset()
+	results := c.GetResults()
+	if len(results) != 1 || results[0].Code != "IR" || results[0].Count != 1 ||
+		results[0].CountTotal != 0 || results[0].BytesUp != 0 {
+		t.Fatalf("Reset should keep live counts and clear history, got %+v", results)
+	}
+	if got := c.GetConnectionRate(); got != 0 {
+		t.Fatalf("Reset should clear the connection rate, got %g", got)
+	}
+}
+
+func TestLookupASNsWithoutDatabases(t *testing.T) {
+	c := NewCollector("")
+
+	results, err := c.LookupASNs([]string{"10.0.0.1", "192.168.1.1", "fd00::1"})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("private IPs should be skipped, got %v, %v", results, err)
+	}
+	if _, err := c.LookupASNs([]string{"10.0.0.1", "not-an-ip"}); err == nil {
+		t.Fatal("expected an error for an invalid IP")
+	}
+	if _, err := c.LookupASNs([]string{"8.8.8.8"}); err == nil {
+		t.Fatal("expected an error when the country database isn't open")
+	}
+	if asn, org := c.lookupNetwork(net.ParseIP("8.8.8.8")); asn != 0 || org != "" {
+		t.Fatalf("lookupNetwork without an ASN database = %d, %q", asn, org)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// centroidsCSV maps ISO country codes to a representative point (code,latitude,longitude)
+//
+//go:embed centroids.csv
+var centroidsCSV string
+
+var (
+	centroidsOnce sync.Once
+	centroids     map[string][2]float64 // code -> [longitude, latitude]
+)
+
+// FeatureCollection is a GeoJSON FeatureCollection of per-country points.
+// Results with no known centroid (including RELAY) are listed in Unmapped
+// as a foreign member rather than dropped.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+	Unmapped []Result  `json:"unmapped"`
+}
+
+// Feature is a GeoJSON point feature for one country
+type Feature struct {
+	Type       string            `json:"type"`
+	Geometry   Point             `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// Point is a GeoJSON point geometry
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // longitude, latitude
+}
+
+// FeatureProperties holds the per-country values; Weight is the live client
+// count for heatmap layers
+type FeatureProperties struct {
+	Code       string `json:"code"`
+	Country    string `json:"country"`
+	Weight     int    `json:"weight"`
+	CountTotal int    `json:"count_total"`
+	BytesUp    int64  `json:"bytes_up"`
+	BytesDown  int64  `json:"bytes_down"`
+}
+
+// loadCentroids parses the embedded centroid table
+func loadCentroids() {
+	centroids = make(map[string][2]float64)
+	records, err := csv.NewReader(strings.NewReader(centroidsCSV)).ReadAll()
+	if err != nil {
+		return
+	}
+	for _, rec := range records[1:] {
+		lat, err1 := strconv.ParseFloat(rec[1], 64)
+		lon, err2 := strconv.ParseFloat(rec[2], 64)
+		if err1 == nil && err2 == nil {
+			centroids[rec[0]] = [2]float64{lon, lat}
+		}
+	}
+}
+
+// Centroid returns the [longitude, latitude] point used for a country code
+func Centroid(code string) ([2]float64, bool) {
+	centroidsOnce.Do(loadCentroids)
+	c, ok := centroids[code]
+	return c, ok
+}
+
+// ToGeoJSON converts results to a FeatureCollection with one point per country
+func ToGeoJSON(results []Result) FeatureCollection {
+	fc := FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []Feature{},
+		Unmapped: []Result{},
+	}
+	for _, r := range results {
+		coords, ok := Centroid(r.Code)
+		if !ok {
+			fc.Unmapped = append(fc.Unmapped, r)
+			continue
+		}
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			Geometry: Point{Type: "Point", Coordinates: coords},
+			Properties: FeatureProperties{
+				Code:       r.Code,
+				Country:    r.Country,
+				Weight:     r.Count,
+				CountTotal: r.CountTotal,
+				BytesUp:    r.BytesUp,
+				BytesDown:  r.BytesDown,
+			},
+		})
+	}
+	return fc
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import "testing"
+
+func TestToGeoJSON(t *testing.T) {
+	fc := ToGeoJSON([]Result{
+		{Code: "IR", Country: "Iran", Count: 4, CountTotal: 9},
+		{Code: "RELAY", Country: "Relay", Count: 2, CountTotal: 3},
+		{Code: "ZZ", Country: "Unknown", Count: 1, CountTotal: 1},
+	})
+
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1 {
+		t.Fatalf("expected one feature, got %+v", fc)
+	}
+	f := fc.Features[0]
+	if f.Properties.Code != "IR" || f.Properties.Weight != 4 || f.Geometry.Type != "Point" {
+		t.Fatalf("unexpected feature %+v", f)
+	}
+	// Coordinates are GeoJSON order: longitude first
+	if lon, lat := f.Geometry.Coordinates[0], f.Geometry.Coordinates[1]; lon < 44 || lon > 64 || lat < 25 || lat > 40 {
+		t.Fatalf("IR centroid out of range: %v", f.Geometry.Coordinates)
+	}
+
+	if len(fc.Unmapped) != 2 || fc.Unmapped[0].Code != "RELAY" || fc.Unmapped[1].Code != "ZZ" {
+		t.Fatalf("expected RELAY and ZZ unmapped, got %+v", fc.Unmapped)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Observation is one resolved client connection, as written by ObservationLog
+type Observation struct {
+	Timestamp string `json:"ts"`
+	IPHash    string `json:"ip_hash"`
+	Code      string `json:"code"`
+	Country   string `json:"country"`
+}
+
+// observationQueueSize is how many observations can wait to be written
+// before new ones are dropped
+const observationQueueSize = 1024
+
+// ObservationLog writes each resolved connection as a JSON line. IPs are
+// never written: each is replaced by a keyed hash that is stable for the
+// life of the log, so repeat visits can be counted within a run but not
+// reversed or joined across runs. Lines are written in the background, so
+// a slow file or pipe never holds up connection tracking.
+type ObservationLog struct {
+	key    []byte
+	mu     sync.Mutex
+	queue  chan Observation
+	closed bool
+	done   chan struct{}
+}
+
+// NewObservationLog creates an ObservationLog writing to w with a fresh hash
+// key. Close must be called to flush pending lines and stop the writer.
+func NewObservationLog(w io.Writer) *ObservationLog {
+	key := make([]byte, 32)
+	rand.Read(key)
+	l := &ObservationLog{
+		key:   key,
+		queue: make(chan Observation, observationQueueSize),
+		done:  make(chan struct{}),
+	}
+	go l.run(json.NewEncoder(w))
+	return l
+}
+
+// WithObservationLog makes the Collector write every resolved connection to l
+func WithObservationLog(l *ObservationLog) Option {
+	return func(c *Collector) {
+		c.observations = l
+	}
+}
+
+// Close writes any queued observations and stops the writer. Observations
+// made after Close are dropped.
+func (l *ObservationLog) Close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	<-l.done
+}
+
+// run writes queued observations until Close, ignoring write errors so a
+// full disk or closed pipe never affects geo tracking
+func (l *ObservationLog) run(enc *json.Encoder) {
+	defer close(l.done)
+	for o := range l.queue {
+		enc.Encode(o)
+	}
+}
+
+// hashIP returns a short keyed hash of an IP
+func (l *ObservationLog) hashIP(ipStr string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(ipStr))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// observe queues one observation without blocking, so it is safe to call
+// with the Collector's lock held. It is dropped if the writer has fallen
+// observationQueueSize lines behind.
+func (l *ObservationLog) observe(ipStr, code, country string) {
+	if l == nil {
+		return
+	}
+	o := Observation{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		IPHash:    l.hashIP(ipStr),
+		Code:      code,
+		Country:   country,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	select {
+	case l.queue <- o:
+	default:
+	}
+}
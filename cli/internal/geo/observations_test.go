@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObservationLog(t *testing.T) {
+	var out bytes.Buffer
+	log := NewObservationLog(&out)
+	c := NewCollector("", WithObservationLog(log))
+
+	c.ConnectRelay("203.0.113.5")
+	c.ConnectRelay("203.0.113.5")
+	c.ConnectRelay("198.51.100.7")
+	log.Close()
+	written := out.String()
+
+	var observations []Observation
+	scanner := bufio.NewScanner(strings.NewReader(written))
+	for scanner.Scan() {
+		var o Observation
+		if err := json.Unmarshal(scanner.Bytes(), &o); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		observations = append(observations, o)
+	}
+
+	if len(observations) != 3 {
+		t.Fatalf("expected 3 observations, got %d", len(observations))
+	}
+	if strings.Contains(written, "203.0.113.5") {
+		t.Fatal("raw IP written to observation log")
+	}
+	if observations[0].IPHash != observations[1].IPHash || observations[0].IPHash == observations[2].IPHash {
+		t.Fatalf("hashes should match only for the same IP: %+v", observations)
+	}
+	if observations[0].Code != "RELAY" || observations[0].Timestamp == "" {
+		t.Fatalf("unexpected observation %+v", observations[0])
+	}
+}
+
+func TestObservationLogDoesNotBlock(t *testing.T) {
+	r, w := io.Pipe()
+	log := NewObservationLog(w)
+	c := NewCollector("", WithObservationLog(log))
+
+	// Nothing reads the pipe, so the writer stalls on the first line
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < observationQueueSize*2; i++ {
+			c.ConnectRelay("203.0.113.5")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection tracking blocked on a stalled observation log")
+	}
+
+	r.Close()
+	log.Close()
+	c.ConnectRelay("203.0.113.5")
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResultsFile is the default checkpoint file name in the data directory
+const ResultsFile = "geo_results.json"
+
+// savedResults is the on-disk checkpoint format
+type savedResults struct {
+	Since   time.Time `json:"since"` // When these totals started accumulating
+	SavedAt time.Time `json:"saved_at"`
+	Results []Result  `json:"results"`
+}
+
+// WithCheckpoint makes the Collector reload per-country totals from path on
+// Start, save them every interval, and save once more on Stop. Totals from
+// before a restart are added to the new run's, so a client seen in both runs
+// is counted twice in count_total.
+func WithCheckpoint(path string, interval time.Duration) Option {
+	return func(c *Collector) {
+		c.checkpointPath = path
+		c.checkpointInterval = interval
+	}
+}
+
+// SaveResults writes the current per-country results to path. The file is
+// replaced atomically so a crash mid-write leaves the previous checkpoint.
+func (c *Collector) SaveResults(path string) error {
+	results := c.GetResults()
+	c.mu.RLock()
+	saved := savedResults{Since: c.since, SavedAt: time.Now().UTC(), Results: results}
+	c.mu.RUnlock()
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal geo results: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create geo results file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write geo results: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write geo results: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write geo results: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write geo results: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace geo results file: %w", err)
+	}
+	return nil
+}
+
+// LoadResults restores totals saved by SaveResults. They are reported by
+// GetResults on top of this run's counts; live counts are not restored.
+func (c *Collector) LoadResults(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var saved savedResults
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse geo results %s: %w", path, err)
+	}
+
+	restored := make(map[string]Result, len(saved.Results))
+	for _, r := range saved.Results {
+		r.Count = 0
+		restored[r.Code] = r
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.restored = restored
+	if !saved.Since.IsZero() && saved.Since.Before(c.since) {
+		c.since = saved.Since
+	}
+	return nil
+}
+
+// addRestored merges totals from a previous run into results. Must be called with lock held.
+func (c *Collector) addRestored(results []Result) []Result {
+	if len(c.restored) == 0 {
+		return results
+	}
+
+	index := make(map[string]int, len(results))
+	for i, r := range results {
+		index[r.Code] = i
+	}
+	for code, r := range c.restored {
+		i, ok := index[code]
+		if !ok {
+			results = append(results, r)
+			continue
+		}
+		results[i].CountTotal += r.CountTotal
+		results[i].BytesUp += r.BytesUp
+		results[i].BytesDown += r.BytesDown
+	}
+	return results
+}
+
+// restoreCheckpoint loads the checkpoint file if one exists
+func (c *Collector) restoreCheckpoint() {
+	err := c.LoadResults(c.checkpointPath)
+	switch {
+	case err == nil:
+		c.logf("[GEO] Restored totals from %s\n", c.checkpointPath)
+	case !errors.Is(err, os.ErrNotExist):
+		c.logf("[WARN] Geo totals not restored: %v\n", err)
+	}
+}
+
+// checkpoint saves results every checkpoint interval until ctx is done
+func (c *Collector) checkpoint(ctx context.Context) {
+	ticker := time.NewTicker(c.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.SaveResults(c.checkpointPath); err != nil {
+				c.logf("[WARN] %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoadResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ResultsFile)
+
+	before := NewCollector("")
+	ir := before.newCountryData("Iran")
+	ir.live = 2
+	ir.addIP("203.0.113.1")
+	ir.addIP("203.0.113.2")
+	ir.bytesDown = 500
+	before.countries["IR"] = ir
+	if err := before.SaveResults(path); err != nil {
+		t.Fatalf("SaveResults: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the results file, got %v (%v)", entries, err)
+	}
+
+	after := NewCollector("")
+	if err := after.LoadResults(path); err != nil {
+		t.Fatalf("LoadResults: %v", err)
+	}
+	if results := after.GetResults(); len(results) != 1 || results[0].Count != 0 ||
+		results[0].CountTotal != 2 || results[0].BytesDown != 500 {
+		t.Fatalf("expected restored totals without live count, got %+v", results)
+	}
+
+	cn := after.newCountryData("China")
+	cn.addIP("198.51.100.1")
+	after.countries["CN"] = cn
+	ir = after.newCountryData("Iran")
+	ir.live = 1
+	ir.addIP("203.0.113.3")
+	ir.bytesDown = 100
+	after.countries["IR"] = ir
+
+	results := after.GetResults()
+	if len(results) != 2 || results[0].Code != "IR" || results[0].Count != 1 ||
+		results[0].CountTotal != 3 || results[0].BytesDown != 600 {
+		t.Fatalf("expected restored totals merged with new counts, got %+v", results)
+	}
+
+	if err := after.LoadResults(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist error for a missing checkpoint, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultRateWindow is the period GetConnectionRate averages over
+const DefaultRateWindow = time.Minute
+
+// WithRateWindow sets the period GetConnectionRate averages new client IPs
+// over. Shorter windows react faster to surges but are noisier. A window
+// of zero or less keeps DefaultRateWindow.
+func WithRateWindow(d time.Duration) Option {
+	return func(c *Collector) {
+		if d > 0 {
+			c.rateWindow = d
+		}
+	}
+}
+
+// noteNewIP records a connection for the connection rate. An IP counts once
+// per window however often it reconnects. Must be called with lock held.
+func (c *Collector) noteNewIP(ipStr string, now time.Time) {
+	if seen, ok := c.rateSeen[ipStr]; ok && now.Sub(seen) < c.rateWindow {
+		return
+	}
+	c.rateSeen[ipStr] = now
+}
+
+// GetConnectionRate returns new client IPs per second, averaged over the
+// rate window. Relay connections are not included. For the first window
+// after start the average is over the full window, so it reads low.
+func (c *Collector) GetConnectionRate() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cutoff := time.Now().Add(-c.rateWindow)
+	count := 0
+	for _, seen := range c.rateSeen {
+		if seen.After(cutoff) {
+			count++
+		}
+	}
+	return float64(count) / c.rateWindow.Seconds()
+}
+
+// pruneRate periodically drops IPs that have left the rate window
+func (c *Collector) pruneRate(ctx context.Context) {
+	ticker := time.NewTicker(c.rateWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			for ip, seen := range c.rateSeen {
+				if now.Sub(seen) >= c.rateWindow {
+					delete(c.rateSeen, ip)
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package geo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetConnectionRate(t *testing.T) {
+	c := NewCollector("", WithRateWindow(10*time.Second))
+	now := time.Now()
+
+	c.mu.Lock()
+	c.noteNewIP("203.0.113.1", now)
+	c.noteNewIP("203.0.113.1", now) // reconnect inside the window
+	c.noteNewIP("203.0.113.2", now)
+	c.noteNewIP("203.0.113.3", now.Add(-time.Minute)) // outside the window
+	c.mu.Unlock()
+
+	if got := c.GetConnectionRate(); got != 0.2 {
+		t.Fatalf("expected 2 new IPs over 10s = 0.2/s, got %g", got)
+	}
+
+	// An IP counts again once it has left the window
+	c.mu.Lock()
+	c.noteNewIP("203.0.113.3", now)
+	c.mu.Unlock()
+	if got := c.GetConnectionRate(); got != 0.3 {
+		t.Fatalf("expected 0.3/s after a returning IP, got %g", got)
+	}
+}
+
+func TestWithRateWindowIgnoresNonPositive(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		c := NewCollector("", WithRateWindow(d))
+		if c.rateWindow != DefaultRateWindow {
+			t.Fatalf("WithRateWindow(%v) set window %v, expected %v", d, c.rateWindow, DefaultRateWindow)
+		}
+		if got := c.GetConnectionRate(); got != 0 {
+			t.Fatalf("expected rate 0 with no connections, got %g", got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package httpapi serves live relay stats as JSON for dashboards
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+// API provides the data behind the JSON endpoints
+type API struct {
+	Stats func() any            // Current relay stats
+	Geo   func() *geo.Collector // Current geo collector, or nil if geo tracking is off
+	Ready func() bool           // Connected to the broker and accepting clients
+}
+
+// GeoResponse is the body of GET /geo
+type GeoResponse struct {
+	Since     time.Time    `json:"since"` // When the totals started accumulating
+	Timestamp time.Time    `json:"timestamp"`
+	Results   []geo.Result `json:"results"`
+}
+
+// Mux is where handlers are registered, e.g. an *http.ServeMux or the metrics server
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Register adds GET /stats, /geo and /ready to mux
+func (a *API) Register(mux Mux) {
+	mux.Handle("/stats", http.HandlerFunc(a.serveStats))
+	mux.Handle("/geo", http.HandlerFunc(a.serveGeo))
+	mux.Handle("/ready", http.HandlerFunc(a.serveReady))
+}
+
+func (a *API) serveStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.Stats())
+}
+
+func (a *API) serveGeo(w http.ResponseWriter, r *http.Request) {
+	collector := a.Geo()
+	if collector == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "geo tracking is not enabled"})
+		return
+	}
+	writeJSON(w, http.StatusOK, GeoResponse{
+		Since:     collector.Since(),
+		Timestamp: time.Now().UTC(),
+		Results:   collector.GetResults(),
+	})
+}
+
+// serveReady answers 200 once the relay is live and 503 before, for load
+// balancer and orchestrator readiness checks
+func (a *API) serveReady(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusOK
+	ready := a.Ready()
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, map[string]bool{"ready": ready})
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+func TestEndpoints(t *testing.T) {
+	ready := false
+	var collector *geo.Collector
+	api := &API{
+		Stats: func() any { return map[string]int{"connectedClients": 3} },
+		Geo:   func() *geo.Collector { return collector },
+		Ready: func() bool { return ready },
+	}
+	mux := http.NewServeMux()
+	api.Register(mux)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("%s: Content-Type = %q", path, ct)
+		}
+		return rec
+	}
+
+	if rec := get("/stats"); rec.Code != http.StatusOK || rec.Body.String() != "{\"connectedClients\":3}\n" {
+		t.Fatalf("/stats: %d %s", rec.Code, rec.Body)
+	}
+	if rec := get("/ready"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/ready before live: %d", rec.Code)
+	}
+	ready = true
+	if rec := get("/ready"); rec.Code != http.StatusOK {
+		t.Fatalf("/ready when live: %d", rec.Code)
+	}
+
+	if rec := get("/geo"); rec.Code != http.StatusNotFound {
+		t.Fatalf("/geo without geo: %d", rec.Code)
+	}
+	collector = geo.NewCollector("")
+	rec := get("/geo")
+	var body GeoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK || body.Since.IsZero() {
+		t.Fatalf("/geo: %d %s (%v)", rec.Code, rec.Body, err)
+	}
+}
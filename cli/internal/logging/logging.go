@@ -2,15 +2,26 @@ package logging
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 )
 
 const TimeFormat = "2006-01-02 15:04:05"
 
+// output is where Printf and Println write
+var output io.Writer = os.Stdout
+
+// SetOutput sends Printf and Println to w instead of stdout. Set it before
+// anything logs; it is not safe to change concurrently.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
 func Printf(format string, args ...any) {
-	fmt.Printf("%s "+format, append([]any{time.Now().Format(TimeFormat)}, args...)...)
+	fmt.Fprintf(output, "%s "+format, append([]any{time.Now().Format(TimeFormat)}, args...)...)
 }
 
 func Println(args ...any) {
-	fmt.Println(append([]any{time.Now().Format(TimeFormat)}, args...)...)
+	fmt.Fprintln(output, append([]any{time.Now().Format(TimeFormat)}, args...)...)
 }
@@ -0,0 +1,76 @@
+//go:build !windows
+
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/coreos/go-systemd/v22/journal"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"daemon": syslog.LOG_DAEMON,
+	"user":   syslog.LOG_USER,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogWriter sends lines to the local syslog daemon
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+// openSysLog connects to the local syslog daemon with the given facility and tag
+func openSysLog(facility, tag string) (sysLogger, error) {
+	f, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q (use daemon, user or local0-local7)", facility)
+	}
+	w, err := syslog.New(f|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return syslogWriter{w: w}, nil
+}
+
+func (s syslogWriter) log(pri journal.Priority, msg string) error {
+	switch pri {
+	case journal.PriErr:
+		return s.w.Err(msg)
+	case journal.PriWarning:
+		return s.w.Warning(msg)
+	case journal.PriDebug:
+		return s.w.Debug(msg)
+	}
+	return s.w.Info(msg)
+}
+
+func (s syslogWriter) close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,59 @@
+//go:build windows
+
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package logging
+
+import (
+	"github.com/coreos/go-systemd/v22/journal"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventID is the Event Log ID used for all conduit messages
+const eventID = 1
+
+// eventLogWriter sends lines to the Windows Event Log
+type eventLogWriter struct {
+	l *eventlog.Log
+}
+
+// openSysLog opens the Windows Event Log with tag as the event source. The
+// facility has no Windows equivalent and is ignored.
+func openSysLog(facility, tag string) (sysLogger, error) {
+	l, err := eventlog.Open(tag)
+	if err != nil {
+		return nil, err
+	}
+	return eventLogWriter{l: l}, nil
+}
+
+func (e eventLogWriter) log(pri journal.Priority, msg string) error {
+	switch pri {
+	case journal.PriErr:
+		return e.l.Error(eventID, msg)
+	case journal.PriWarning:
+		return e.l.Warning(eventID, msg)
+	}
+	return e.l.Info(eventID, msg)
+}
+
+func (e eventLogWriter) close() error {
+	return e.l.Close()
+}
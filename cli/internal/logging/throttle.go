@@ -0,0 +1,257 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/journal"
+)
+
+const (
+	// minFreeDiskBytes is the free space below which file output is paused
+	minFreeDiskBytes  = 100 * 1024 * 1024
+	diskCheckInterval = 30 * time.Second
+
+	// flushInterval is how soon pending repeat and suppression counts are
+	// reported when no new line arrives to flush them
+	flushInterval = 5 * time.Second
+)
+
+// Throttle rate limits log output and coalesces consecutive repeats of the
+// same line, so a chatty or crash-looping relay can't fill a small disk.
+// When the output is a regular file, writes are paused while free space on
+// its filesystem is below minFreeDiskBytes. When stdout is connected to the
+// systemd journal, lines are sent with the native protocol instead so that
+// structured fields survive.
+type Throttle struct {
+	mu         sync.Mutex
+	out        io.Writer
+	path       string // set when out is a regular file
+	journal    bool   // send to journald instead of out
+	tag        string // prefixed to every line as "[tag] "
+	sys        sysLogger
+	maxPerSec  int    // 0 = unlimited
+	window     int64  // current one-second window (unix seconds)
+	count      int    // lines written in the current window
+	suppressed int    // lines dropped by the rate limit
+	last       string // last line written
+	repeats    int
+	flushTimer *time.Timer // pending flush of repeats and suppressed (nil = none)
+	lowDisk    bool
+	lastCheck  time.Time
+}
+
+// NewThrottle creates a Throttle writing to out at most maxPerSec lines per
+// second (0 = unlimited)
+func NewThrottle(out *os.File, maxPerSec int) *Throttle {
+	t := &Throttle{out: out, maxPerSec: maxPerSec}
+	if info, err := out.Stat(); err == nil && info.Mode().IsRegular() {
+		t.path = out.Name()
+	}
+	if out == os.Stdout && journal.Enabled() {
+		t.journal, _ = journal.StdoutIsJournalStream()
+	}
+	return t
+}
+
+// sysLogger is a system log destination: syslog on Unix, the Event Log on Windows
+type sysLogger interface {
+	log(pri journal.Priority, msg string) error
+	close() error
+}
+
+// UseSysLog routes lines to the system log instead of out until Close. On Unix,
+// facility is a syslog facility such as "daemon" or "local0"; tag names the
+// program in syslog or the event source on Windows. Lines the system log
+// can't take, and lines after Close, go to stderr rather than out, so stdout
+// stays free for data such as --geo-observations.
+func (t *Throttle) UseSysLog(facility, tag string) error {
+	sys, err := openSysLog(facility, tag)
+	if err != nil {
+		return fmt.Errorf("failed to open system log: %w", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sys = sys
+	t.out = os.Stderr
+	t.path = ""
+	return nil
+}
+
+// SetTag prefixes every subsequent line with "[tag] " so lines from several
+// stations can be told apart in a shared log
+func (t *Throttle) SetTag(tag string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tag = tag
+}
+
+// Printf formats and writes a line subject to rate limiting and coalescing
+func (t *Throttle) Printf(format string, args ...any) {
+	t.PrintFields(nil, format, args...)
+}
+
+// PrintFields is like Printf but attaches structured fields (e.g. CLIENTS)
+// to the entry when logging to journald. Fields are dropped for text output.
+func (t *Throttle) PrintFields(fields map[string]string, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.checkDisk() {
+		return
+	}
+
+	now := time.Now().Unix()
+	if now != t.window {
+		t.window = now
+		t.count = 0
+		t.flushSuppressed()
+	}
+	if t.maxPerSec > 0 && t.count >= t.maxPerSec {
+		t.suppressed++
+		t.scheduleFlush()
+		return
+	}
+
+	// Only lines that were written can repeat
+	if msg == t.last {
+		t.repeats++
+		t.scheduleFlush()
+		return
+	}
+	t.flushRepeats()
+	t.last = msg
+	t.count++
+	t.write(msg, fields)
+}
+
+// Close reports any pending repeat and suppression counts and closes the
+// system log. Later lines go to out, or stderr if the system log was used.
+func (t *Throttle) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.flushTimer != nil {
+		t.flushTimer.Stop()
+		t.flushTimer = nil
+	}
+	t.flushRepeats()
+	t.flushSuppressed()
+	if t.sys == nil {
+		return nil
+	}
+	err := t.sys.close()
+	t.sys = nil
+	return err
+}
+
+// scheduleFlush reports pending counts after flushInterval unless a new
+// line does it first. Must be called with lock held.
+func (t *Throttle) scheduleFlush() {
+	if t.flushTimer != nil {
+		return
+	}
+	t.flushTimer = time.AfterFunc(flushInterval, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.flushTimer = nil
+		t.flushRepeats()
+		t.flushSuppressed()
+	})
+}
+
+// write sends a line to the system log, journald or out, falling back to out
+// if the system log or journal send fails. Must be called with lock held.
+func (t *Throttle) write(msg string, fields map[string]string) {
+	if t.tag != "" {
+		// Keep leading blank lines ahead of the tag
+		body := strings.TrimLeft(msg, "\n")
+		msg = msg[:len(msg)-len(body)] + "[" + t.tag + "] " + body
+	}
+	if t.sys != nil {
+		if err := t.sys.log(priority(msg), strings.TrimSpace(msg)); err == nil {
+			return
+		}
+	}
+	if t.journal {
+		if err := journal.Send(strings.TrimSpace(msg), priority(msg), fields); err == nil {
+			return
+		}
+	}
+	io.WriteString(t.out, msg)
+}
+
+// priority maps a line's level tag to a journald priority
+func priority(msg string) journal.Priority {
+	switch {
+	case strings.Contains(msg, "[ERROR]"):
+		return journal.PriErr
+	case strings.Contains(msg, "[WARN"), strings.Contains(msg, "WARNING:"):
+		return journal.PriWarning
+	case strings.Contains(msg, "[DEBUG]"):
+		return journal.PriDebug
+	}
+	return journal.PriInfo
+}
+
+// flushRepeats reports coalesced repeats of the last line. Must be called with lock held.
+func (t *Throttle) flushRepeats() {
+	if t.repeats > 0 {
+		t.write(fmt.Sprintf("(previous message repeated %d times)\n", t.repeats), nil)
+		t.repeats = 0
+	}
+}
+
+// flushSuppressed reports lines dropped by the rate limit. Must be called with lock held.
+func (t *Throttle) flushSuppressed() {
+	if t.suppressed > 0 {
+		t.write(fmt.Sprintf("(%d log lines suppressed)\n", t.suppressed), nil)
+		t.suppressed = 0
+	}
+}
+
+// checkDisk reports whether output is paused for low disk space. Must be called with lock held.
+func (t *Throttle) checkDisk() bool {
+	if t.path == "" || time.Since(t.lastCheck) < diskCheckInterval {
+		return t.lowDisk
+	}
+	t.lastCheck = time.Now()
+
+	free, err := freeDiskBytes(t.path)
+	if err != nil {
+		return t.lowDisk
+	}
+	low := free < minFreeDiskBytes
+	if low && !t.lowDisk {
+		t.flushRepeats()
+		t.write(fmt.Sprintf("[WARN] Less than %d MB free for %s, pausing log output\n", minFreeDiskBytes/1024/1024, t.path), nil)
+	} else if !low && t.lowDisk {
+		t.write("[OK] Disk space recovered, resuming log output\n", nil)
+	}
+	t.lowDisk = low
+	return low
+}
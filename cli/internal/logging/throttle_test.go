@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package logging
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/journal"
+)
+
+func TestThrottleTag(t *testing.T) {
+	var out bytes.Buffer
+	throttle := &Throttle{out: &out}
+	throttle.SetTag("conduit-eu")
+
+	throttle.Printf("2026-01-01 00:00:00 [STATS] Connected: %d\n", 3)
+	throttle.Printf("\nWARNING: upgrade\n")
+
+	expected := "[conduit-eu] 2026-01-01 00:00:00 [STATS] Connected: 3\n\n[conduit-eu] WARNING: upgrade\n"
+	if out.String() != expected {
+		t.Fatalf("output = %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestThrottleCoalescesRepeats(t *testing.T) {
+	var out bytes.Buffer
+	throttle := &Throttle{out: &out}
+
+	for range 3 {
+		throttle.Printf("same\n")
+	}
+	throttle.Printf("different\n")
+
+	expected := "same\n(previous message repeated 2 times)\ndifferent\n"
+	if out.String() != expected {
+		t.Fatalf("output = %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestThrottleCloseFlushesRepeats(t *testing.T) {
+	var out bytes.Buffer
+	throttle := &Throttle{out: &out}
+
+	for range 3 {
+		throttle.Printf("same\n")
+	}
+	throttle.Close()
+
+	expected := "same\n(previous message repeated 2 times)\n"
+	if out.String() != expected {
+		t.Fatalf("output = %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestThrottleSuppressedLinesAreNotRepeats(t *testing.T) {
+	var out bytes.Buffer
+	for {
+		// Retry if the one-second window rolled over mid-test
+		out.Reset()
+		start := time.Now().Unix()
+		throttle := &Throttle{out: &out, maxPerSec: 1}
+		throttle.Printf("first\n")
+		throttle.Printf("dropped\n")
+		throttle.Printf("dropped\n")
+		throttle.Close()
+		if time.Now().Unix() == start {
+			break
+		}
+	}
+
+	expected := "first\n(2 log lines suppressed)\n"
+	if out.String() != expected {
+		t.Fatalf("output = %q, expected %q", out.String(), expected)
+	}
+}
+
+// fakeSysLogger records lines and whether it was closed
+type fakeSysLogger struct {
+	lines  []string
+	closed bool
+}
+
+func (f *fakeSysLogger) log(pri journal.Priority, msg string) error {
+	f.lines = append(f.lines, msg)
+	return nil
+}
+
+func (f *fakeSysLogger) close() error {
+	f.closed = true
+	return nil
+}
+
+func TestThrottleCloseClosesSysLog(t *testing.T) {
+	var out bytes.Buffer
+	sys := &fakeSysLogger{}
+	throttle := &Throttle{out: &out, sys: sys}
+
+	throttle.Printf("to syslog\n")
+	throttle.Close()
+	throttle.Printf("after close\n")
+
+	if !sys.closed {
+		t.Fatal("Close left the system log open")
+	}
+	if len(sys.lines) != 1 || sys.lines[0] != "to syslog" {
+		t.Fatalf("system log lines = %q, expected only the line before Close", sys.lines)
+	}
+	if out.String() != "after close\n" {
+		t.Fatalf("output = %q, expected the line after Close", out.String())
+	}
+}
+
+func TestUseSysLogRejectsUnknownFacility(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("facility is ignored by the Event Log")
+	}
+	throttle := &Throttle{}
+	if err := throttle.UseSysLog("mail2", "conduit"); err == nil {
+		t.Fatal("expected error for unknown facility")
+	}
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"net"
+)
+
+// bindError explains a failed listen. For an address already in use it
+// names the conflicting process when it can be found, and the flag to change.
+func bindError(addr string, err error) error {
+	if !isAddrInUse(err) {
+		return fmt.Errorf("failed to bind to %s: %w", addr, err)
+	}
+
+	_, port, splitErr := net.SplitHostPort(addr)
+	if splitErr != nil {
+		return fmt.Errorf("metrics address %s is already in use; choose another with --metrics-addr", addr)
+	}
+	return fmt.Errorf("metrics address %s is already in use by %s; choose another port with --metrics-addr",
+		addr, portOwner(port))
+}
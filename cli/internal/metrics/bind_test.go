@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestStartServerAddressInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	m := New(GaugeFuncs{})
+	err = m.StartServer(listener.Addr().String())
+	if err == nil {
+		t.Fatal("expected bind failure")
+	}
+	if !strings.Contains(err.Error(), "already in use") || !strings.Contains(err.Error(), "--metrics-addr") {
+		t.Fatalf("error is not actionable: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// isAddrInUse reports whether err is WSAEADDRINUSE
+func isAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
+
+// portOwner finds the pid listening on a TCP port using netstat, or says
+// how to look it up
+func portOwner(port string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "netstat", "-ano", "-p", "TCP").Output()
+	if err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			// Proto  Local Address  Foreign Address  State  PID
+			fields := strings.Fields(line)
+			if len(fields) == 5 && fields[3] == "LISTENING" && strings.HasSuffix(fields[1], ":"+port) {
+				return "pid " + fields[4]
+			}
+		}
+	}
+	return fmt.Sprintf("another process (find it with 'netstat -ano | findstr :%s')", port)
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// countryCollector reports live clients per country at scrape time, so
+// countries drop out of the output once their clients leave
+type countryCollector struct {
+	desc    *prometheus.Desc
+	clients func() map[string]int
+}
+
+func newCountryCollector(clients func() map[string]int) *countryCollector {
+	return &countryCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "clients_by_country"),
+			"Number of currently connected clients by country code (RELAY for TURN relay connections)",
+			[]string{"code"}, nil,
+		),
+		clients: clients,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *countryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *countryCollector) Collect(ch chan<- prometheus.Metric) {
+	for code, count := range c.clients() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), code)
+	}
+}
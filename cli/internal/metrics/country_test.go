@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClientsByCountry(t *testing.T) {
+	clients := map[string]int{"IR": 3, "RELAY": 1}
+	c := newCountryCollector(func() map[string]int { return clients })
+
+	expected := `
+# HELP conduit_clients_by_country Number of currently connected clients by country code (RELAY for TURN relay connections)
+# TYPE conduit_clients_by_country gauge
+conduit_clients_by_country{code="IR"} 3
+conduit_clients_by_country{code="RELAY"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A country whose clients have left is no longer reported
+	clients = map[string]int{"IR": 3}
+	if n := testutil.CollectAndCount(c); n != 1 {
+		t.Fatalf("expected 1 series, got %d", n)
+	}
+}
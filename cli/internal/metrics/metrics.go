@@ -22,7 +22,6 @@ package metrics
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"net/http"
 
@@ -52,12 +51,16 @@ type Metrics struct {
 
 	registry *prometheus.Registry
 	server   *http.Server
+	handlers map[string]http.Handler // served alongside /metrics
 }
 
 // GaugeFuncs holds functions that compute metrics at scrape time
 type GaugeFuncs struct {
-	GetUptimeSeconds func() float64
-	GetIdleSeconds   func() float64
+	GetUptimeSeconds    func() float64
+	GetIdleSeconds      func() float64
+	GetUniqueClients    func() float64        // optional, registered only when set
+	GetClientsByCountry func() map[string]int // optional, registered only when set
+	GetConnectionRate   func() float64        // optional, registered only when set
 }
 
 // New creates a new Metrics instance with all metrics registered
@@ -167,6 +170,32 @@ func New(gaugeFuncs GaugeFuncs) *Metrics {
 	registry.MustRegister(m.BytesDownloaded)
 	registry.MustRegister(m.BuildInfo)
 
+	if gaugeFuncs.GetUniqueClients != nil {
+		registry.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "unique_clients_estimate",
+				Help:      "Estimated number of distinct client IPs served since the service started",
+			},
+			gaugeFuncs.GetUniqueClients,
+		))
+	}
+
+	if gaugeFuncs.GetConnectionRate != nil {
+		registry.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "new_clients_per_second",
+				Help:      "New client IPs per second, averaged over the geo rate window",
+			},
+			gaugeFuncs.GetConnectionRate,
+		))
+	}
+
+	if gaugeFuncs.GetClientsByCountry != nil {
+		registry.MustRegister(newCountryCollector(gaugeFuncs.GetClientsByCountry))
+	}
+
 	// Set build info
 
 	buildInfo := buildinfo.GetBuildInfo()
@@ -215,9 +244,20 @@ func (m *Metrics) SetBytesDownloaded(bytes float64) {
 	m.BytesDownloaded.Set(bytes)
 }
 
+// Handle serves handler at pattern on the metrics server. Call before StartServer.
+func (m *Metrics) Handle(pattern string, handler http.Handler) {
+	if m.handlers == nil {
+		m.handlers = make(map[string]http.Handler)
+	}
+	m.handlers[pattern] = handler
+}
+
 // StartServer starts the HTTP server for Prometheus metrics
 func (m *Metrics) StartServer(addr string) error {
 	mux := http.NewServeMux()
+	for pattern, handler := range m.handlers {
+		mux.Handle(pattern, handler)
+	}
 	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
 		EnableOpenMetrics: true,
 	}))
@@ -227,7 +267,7 @@ func (m *Metrics) StartServer(addr string) error {
 	// Create a listener to verify the port is available before starting the server
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to bind to %s: %w", addr, err)
+		return bindError(addr, err)
 	}
 
 	// Start server in background with the pre-created listener
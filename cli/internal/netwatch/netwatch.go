@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package netwatch detects changes to the host's network addresses, such as
+// a laptop waking up on a different network.
+package netwatch
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// settleDelay lets a burst of OS events from one switch settle
+	settleDelay = 2 * time.Second
+
+	// pollInterval is used where the OS offers no change notification
+	pollInterval = 10 * time.Second
+)
+
+// Watch calls onChange whenever the set of addresses on up, non-loopback
+// interfaces changes. OS notifications are used where available and only
+// wake the watcher; the address set decides whether anything changed.
+// Blocks until ctx is cancelled.
+func Watch(ctx context.Context, onChange func()) {
+	events := make(chan struct{}, 1)
+	go notify(ctx, events)
+
+	last := fingerprint()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(settleDelay):
+		}
+		select {
+		case <-events:
+		default:
+		}
+
+		if current := fingerprint(); current != last {
+			last = current
+			onChange()
+		}
+	}
+}
+
+// signal wakes the watcher without blocking
+func signal(events chan<- struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
+
+// poll wakes the watcher every pollInterval
+func poll(ctx context.Context, events chan<- struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			signal(events)
+		}
+	}
+}
+
+// fingerprint summarizes the addresses of up, non-loopback interfaces
+func fingerprint() string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			parts = append(parts, iface.Name+"="+addr.String())
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
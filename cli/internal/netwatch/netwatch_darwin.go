@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package netwatch
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// notify wakes the watcher on routing socket messages, which the kernel
+// sends for address, interface and route changes
+func notify(ctx context.Context, events chan<- struct{}) {
+	fd, err := unix.Socket(unix.AF_ROUTE, unix.SOCK_RAW, unix.AF_UNSPEC)
+	if err != nil {
+		poll(ctx, events)
+		return
+	}
+	// Non-blocking so closing the file interrupts Read
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		poll(ctx, events)
+		return
+	}
+	sock := os.NewFile(uintptr(fd), "route")
+	go func() {
+		<-ctx.Done()
+		sock.Close()
+	}()
+
+	buf := make([]byte, 2048)
+	for {
+		if _, err := sock.Read(buf); err != nil {
+			return
+		}
+		signal(events)
+	}
+}
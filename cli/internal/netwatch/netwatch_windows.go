@@ -0,0 +1,93 @@
+//go:build windows
+
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package netwatch
+
+import (
+	"context"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	iphlpapi                 = windows.NewLazySystemDLL("iphlpapi.dll")
+	procNotifyAddrChange     = iphlpapi.NewProc("NotifyAddrChange")
+	procCancelIPChangeNotify = iphlpapi.NewProc("CancelIPChangeNotify")
+)
+
+// notify wakes the watcher when Windows reports an IPv4 address change.
+// Polling covers IPv6 and the case where the calls are unavailable.
+func notify(ctx context.Context, events chan<- struct{}) {
+	go poll(ctx, events)
+
+	if procNotifyAddrChange.Find() != nil || procCancelIPChangeNotify.Find() != nil {
+		return
+	}
+
+	// changed is signalled by Windows when a request completes, stop when
+	// ctx is cancelled
+	changed, err := windows.CreateEvent(nil, 0, 0, nil)
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(changed)
+	stop, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(stop)
+
+	// The handles stay open until the goroutine is done with stop
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			windows.SetEvent(stop)
+		case <-done:
+		}
+	}()
+	defer wg.Wait()
+	defer close(done)
+
+	overlapped := &windows.Overlapped{}
+	for {
+		*overlapped = windows.Overlapped{HEvent: changed}
+		var handle windows.Handle
+		ret, _, _ := procNotifyAddrChange.Call(uintptr(unsafe.Pointer(&handle)), uintptr(unsafe.Pointer(overlapped)))
+		if windows.Errno(ret) != windows.ERROR_IO_PENDING {
+			return
+		}
+
+		which, err := windows.WaitForMultipleObjects([]windows.Handle{changed, stop}, false, windows.INFINITE)
+		if err != nil || which != windows.WAIT_OBJECT_0 {
+			// Withdraw the pending request so Windows no longer writes to
+			// overlapped or signals changed
+			procCancelIPChangeNotify.Call(uintptr(unsafe.Pointer(overlapped)))
+			return
+		}
+		signal(events)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Service state changes sent by a Notifier
+const (
+	EventStarted      = "started"      // The process started connecting, sent once
+	EventConnected    = "connected"    // Connected to the Psiphon network
+	EventDisconnected = "disconnected" // Lost the broker connection, or the service failed
+	EventStopping     = "stopping"     // The process is shutting down, sent once
+)
+
+// notifyQueueSize is how many events can wait for delivery before new ones
+// are dropped
+const notifyQueueSize = 16
+
+// Event is the JSON body posted for a state change. Text and Content repeat
+// Message so the URL can be a Slack or Discord incoming webhook.
+type Event struct {
+	Event     string `json:"event"`
+	Timestamp string `json:"timestamp"`
+	Station   string `json:"station,omitempty"` // --log-tag, to tell stations apart
+	Message   string `json:"message"`
+	Text      string `json:"text"`
+	Content   string `json:"content"`
+}
+
+// Notifier posts state changes to a webhook in order, in the background, so
+// a slow or dead endpoint never blocks the service
+type Notifier struct {
+	webhook *Webhook
+	station string
+	events  chan Event
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logf    func(format string, args ...any)
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewNotifier starts a Notifier for url. Requests are signed like reports if
+// secret is non-empty. Delivery failures are passed to logf.
+func NewNotifier(url, secret, station string, logf func(format string, args ...any)) *Notifier {
+	webhook := NewWebhook(url, secret)
+	webhook.backoff = 5 * time.Second
+	return newNotifier(webhook, station, logf)
+}
+
+func newNotifier(webhook *Webhook, station string, logf func(format string, args ...any)) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		webhook: webhook,
+		station: station,
+		events:  make(chan Event, notifyQueueSize),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		logf:    logf,
+	}
+	go n.deliver()
+	return n
+}
+
+// Notify queues an event without blocking; it is dropped if the queue is
+// full or the Notifier is closed
+func (n *Notifier) Notify(event, message string) {
+	text := message
+	if n.station != "" {
+		text = fmt.Sprintf("[%s] %s", n.station, message)
+	}
+	e := Event{
+		Event:     event,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Station:   n.station,
+		Message:   message,
+		Text:      text,
+		Content:   text,
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	select {
+	case n.events <- e:
+	default:
+		n.logf("[WARN] Notify queue full, dropped %q event\n", event)
+	}
+}
+
+// Close delivers queued events, giving up on any still pending after timeout
+func (n *Notifier) Close(timeout time.Duration) {
+	n.mu.Lock()
+	n.closed = true
+	close(n.events)
+	n.mu.Unlock()
+
+	select {
+	case <-n.done:
+	case <-time.After(timeout):
+		n.cancel()
+		<-n.done
+	}
+	n.cancel()
+}
+
+// deliver sends queued events one at a time until Close
+func (n *Notifier) deliver() {
+	defer close(n.done)
+	for e := range n.events {
+		if n.ctx.Err() != nil {
+			continue
+		}
+		if err := n.webhook.Send(n.ctx, e); err != nil && n.ctx.Err() == nil {
+			n.logf("[ERROR] Notify %s: %v\n", e.Event, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package report builds periodic summaries of relay activity and state
+// change notifications, and delivers them to an operator's webhooks.
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the request body
+	SignatureHeader = "X-Conduit-Signature"
+
+	maxAttempts    = 3
+	requestTimeout = 30 * time.Second
+	topCountries   = 5
+)
+
+// Summary is the JSON body posted for each report period. Byte counts
+// cover the period only.
+type Summary struct {
+	PeriodStart   string       `json:"periodStart"`
+	PeriodEnd     string       `json:"periodEnd"`
+	UptimeSeconds int64        `json:"uptimeSeconds"`
+	BytesUp       int64        `json:"bytesUp"`
+	BytesDown     int64        `json:"bytesDown"`
+	PeakClients   int          `json:"peakClients"`
+	AvgClients    float64      `json:"avgClients"`
+	TopCountries  []geo.Result `json:"topCountries,omitempty"` // New clients per country during the period
+}
+
+// Tracker accumulates connected-client samples over a report period
+type Tracker struct {
+	peak    int
+	sum     int
+	samples int
+}
+
+// Observe records one sample of the connected client count
+func (t *Tracker) Observe(clients int) {
+	t.peak = max(t.peak, clients)
+	t.sum += clients
+	t.samples++
+}
+
+// Peak returns the highest sample seen
+func (t *Tracker) Peak() int {
+	return t.peak
+}
+
+// Avg returns the mean of the samples seen, or 0 if there were none
+func (t *Tracker) Avg() float64 {
+	if t.samples == 0 {
+		return 0
+	}
+	return float64(t.sum) / float64(t.samples)
+}
+
+// Reset starts a new period
+func (t *Tracker) Reset() {
+	*t = Tracker{}
+}
+
+// TopCountries returns up to n countries that gained the most unique
+// clients between two geo snapshots
+func TopCountries(prev, cur geo.Snapshot, n int) []geo.Result {
+	var top []geo.Result
+	for _, d := range geo.Delta(prev, cur) {
+		if d.CountTotal > 0 {
+			top = append(top, d)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].CountTotal > top[j].CountTotal
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Webhook posts JSON payloads, such as summaries, to an HTTP endpoint
+type Webhook struct {
+	url     string
+	secret  string
+	client  *http.Client
+	backoff time.Duration // wait before the first retry, doubled after each
+}
+
+// NewWebhook creates a Webhook for url. If secret is non-empty, each request
+// is signed so the receiver can verify it came from this station.
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		url:     url,
+		secret:  secret,
+		client:  &http.Client{Timeout: requestTimeout},
+		backoff: 30 * time.Second,
+	}
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of the body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts payload as JSON, retrying network errors and 5xx responses
+func (w *Webhook) Send(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	backoff := w.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == maxAttempts {
+			return fmt.Errorf("failed to send to webhook after %d attempt(s): %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return true, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
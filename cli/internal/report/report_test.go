@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+func TestWebhookSignsAndRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), Sign("secret", body); got != want {
+			t.Errorf("signature = %q, expected %q", got, want)
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "secret")
+	webhook.backoff = 0
+	if err := webhook.Send(context.Background(), Summary{PeakClients: 3}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, expected 2", attempts)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "")
+	webhook.backoff = 0
+	if err := webhook.Send(context.Background(), Summary{}); err == nil {
+		t.Fatal("expected error for 403")
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, expected 1", attempts)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		received = append(received, e)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, "", "eu-1", t.Logf)
+	notifier.Notify(EventStarted, "Conduit started")
+	notifier.Notify(EventConnected, "Conduit connected")
+	notifier.Notify(EventStopping, "Conduit stopping")
+	notifier.Close(5 * time.Second)
+	notifier.Notify(EventConnected, "late notice after Close is dropped")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 {
+		t.Fatalf("received %d events, expected 3", len(received))
+	}
+	for i, kind := range []string{EventStarted, EventConnected, EventStopping} {
+		if received[i].Event != kind {
+			t.Fatalf("event %d = %q, expected %q", i, received[i].Event, kind)
+		}
+	}
+	if e := received[0]; e.Station != "eu-1" || e.Text != "[eu-1] Conduit started" || e.Content != e.Text {
+		t.Fatalf("unexpected event body %+v", e)
+	}
+}
+
+func TestNotifierCloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	notifier := NewNotifier(server.URL, "", "", t.Logf)
+	notifier.Notify(EventStopping, "Conduit stopping")
+
+	start := time.Now()
+	notifier.Close(100 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Close waited %s for a hung endpoint", elapsed)
+	}
+}
+
+func TestTopCountries(t *testing.T) {
+	prev := geo.NewSnapshot([]geo.Result{
+		{Code: "IR", Country: "Iran", CountTotal: 10},
+		{Code: "CN", Country: "China", CountTotal: 5},
+	})
+	cur := geo.NewSnapshot([]geo.Result{
+		{Code: "IR", Country: "Iran", CountTotal: 12},
+		{Code: "CN", Country: "China", CountTotal: 5},
+		{Code: "RU", Country: "Russia", CountTotal: 7},
+	})
+
+	top := TopCountries(prev, cur, 5)
+	if len(top) != 2 || top[0].Code != "RU" || top[1].Code != "IR" || top[1].CountTotal != 2 {
+		t.Fatalf("unexpected top countries %+v", top)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	var tracker Tracker
+	for _, n := range []int{2, 6, 4} {
+		tracker.Observe(n)
+	}
+	if tracker.Peak() != 6 || tracker.Avg() != 4 {
+		t.Fatalf("peak = %d, avg = %v", tracker.Peak(), tracker.Avg())
+	}
+	tracker.Reset()
+	if tracker.Peak() != 0 || tracker.Avg() != 0 {
+		t.Fatal("Reset did not clear the tracker")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package statsink
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StatsdEncoder renders samples as statsd gauges
+type StatsdEncoder struct{}
+
+// Encode implements Encoder
+func (StatsdEncoder) Encode(s Sample) []string {
+	lines := []string{
+		fmt.Sprintf("%s.connecting_clients:%d|g", metricPrefix, s.ConnectingClients),
+		fmt.Sprintf("%s.connected_clients:%d|g", metricPrefix, s.ConnectedClients),
+		fmt.Sprintf("%s.bytes_up:%d|g", metricPrefix, s.BytesUp),
+		fmt.Sprintf("%s.bytes_down:%d|g", metricPrefix, s.BytesDown),
+	}
+	for _, r := range s.Geo {
+		lines = append(lines, fmt.Sprintf("%s.clients_by_country.%s:%d|g", metricPrefix, r.Code, r.Count))
+	}
+	return lines
+}
+
+// InfluxEncoder renders samples in InfluxDB line protocol
+type InfluxEncoder struct{}
+
+// Encode implements Encoder
+func (InfluxEncoder) Encode(s Sample) []string {
+	ts := s.Time.UnixNano()
+	lines := []string{
+		fmt.Sprintf("%s connecting_clients=%di,connected_clients=%di,bytes_up=%di,bytes_down=%di %d",
+			metricPrefix, s.ConnectingClients, s.ConnectedClients, s.BytesUp, s.BytesDown, ts),
+	}
+	for _, r := range s.Geo {
+		lines = append(lines, fmt.Sprintf("%s_geo,country=%s clients=%di,clients_total=%di,bytes_up=%di,bytes_down=%di %d",
+			metricPrefix, escapeTag(r.Code), r.Count, r.CountTotal, r.BytesUp, r.BytesDown, ts))
+	}
+	return lines
+}
+
+// escapeTag escapes a line protocol tag value
+func escapeTag(v string) string {
+	return strings.NewReplacer(",", "\\,", "=", "\\=", " ", "\\ ").Replace(v)
+}
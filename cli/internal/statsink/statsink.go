@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package statsink pushes periodic stats to statsd or InfluxDB over UDP
+package statsink
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+const (
+	DefaultInterval = 10 * time.Second
+	minInterval     = time.Second
+
+	// maxDatagramSize keeps packets under a typical path MTU
+	maxDatagramSize = 1400
+
+	metricPrefix = "conduit"
+)
+
+// Sample is one set of values pushed to a sink
+type Sample struct {
+	Time              time.Time
+	ConnectingClients int
+	ConnectedClients  int
+	BytesUp           int64
+	BytesDown         int64
+	Geo               []geo.Result // empty when geo is disabled
+}
+
+// Encoder renders a sample as lines in a wire format
+type Encoder interface {
+	Encode(s Sample) []string
+}
+
+// Sink receives periodic stats samples
+type Sink interface {
+	Send(s Sample) error
+	Close() error
+}
+
+// Config is a parsed sink URL
+type Config struct {
+	Addr     string
+	Format   string // "statsd" or "influx"
+	Interval time.Duration
+}
+
+// ParseURL parses a sink URL of the form
+// udp://host:port?format=statsd|influx&interval=10s
+func ParseURL(raw string) (Config, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid stats sink URL: %w", err)
+	}
+	if u.Scheme != "udp" {
+		return Config{}, fmt.Errorf("unsupported stats sink scheme %q (only udp is supported)", u.Scheme)
+	}
+	if u.Port() == "" {
+		return Config{}, fmt.Errorf("stats sink URL must include a port")
+	}
+
+	cfg := Config{
+		Addr:     u.Host,
+		Format:   u.Query().Get("format"),
+		Interval: DefaultInterval,
+	}
+	if cfg.Format == "" {
+		cfg.Format = "statsd"
+	}
+	if _, err := newEncoder(cfg.Format); err != nil {
+		return Config{}, err
+	}
+	if v := u.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid stats sink interval %q: %w", v, err)
+		}
+		if d < minInterval {
+			return Config{}, fmt.Errorf("stats sink interval must be at least %s", minInterval)
+		}
+		cfg.Interval = d
+	}
+
+	return cfg, nil
+}
+
+func newEncoder(format string) (Encoder, error) {
+	switch format {
+	case "statsd":
+		return StatsdEncoder{}, nil
+	case "influx":
+		return InfluxEncoder{}, nil
+	}
+	return nil, fmt.Errorf("unsupported stats sink format %q (use statsd or influx)", format)
+}
+
+// udpSink sends encoded lines in as few datagrams as possible
+type udpSink struct {
+	conn    net.Conn
+	encoder Encoder
+}
+
+// Open creates a sink for the given config
+func Open(cfg Config) (Sink, error) {
+	encoder, err := newEncoder(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats sink: %w", err)
+	}
+	return &udpSink{conn: conn, encoder: encoder}, nil
+}
+
+// Send encodes and writes a sample, packing lines into datagrams
+func (s *udpSink) Send(sample Sample) error {
+	var buf []byte
+	for _, line := range s.encoder.Encode(sample) {
+		if len(buf) > 0 && len(buf)+1+len(line) > maxDatagramSize {
+			if _, err := s.conn.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+		if len(buf) > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, line...)
+	}
+	if len(buf) > 0 {
+		if _, err := s.conn.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying connection
+func (s *udpSink) Close() error {
+	return s.conn.Close()
+}
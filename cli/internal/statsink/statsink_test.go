@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2026, Psiphon Inc.
+ * All rights reserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package statsink
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Psiphon-Inc/conduit/cli/internal/geo"
+)
+
+func TestParseURL(t *testing.T) {
+	cfg, err := ParseURL("udp://127.0.0.1:8125?format=influx&interval=30s")
+	if err != nil {
+		t.Fatalf("ParseURL: %v", err)
+	}
+	if cfg.Addr != "127.0.0.1:8125" || cfg.Format != "influx" || cfg.Interval != 30*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	cfg, err = ParseURL("udp://localhost:8125")
+	if err != nil {
+		t.Fatalf("ParseURL: %v", err)
+	}
+	if cfg.Format != "statsd" || cfg.Interval != DefaultInterval {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+
+	for _, bad := range []string{
+		"tcp://localhost:8125",
+		"udp://localhost",
+		"udp://localhost:8125?format=graphite",
+		"udp://localhost:8125?interval=10ms",
+	} {
+		if _, err := ParseURL(bad); err == nil {
+			t.Fatalf("ParseURL(%q) succeeded, expected error", bad)
+		}
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	sample := Sample{
+		Time:             time.Unix(1700000000, 0),
+		ConnectedClients: 12,
+		BytesUp:          100,
+		BytesDown:        200,
+		Geo:              []geo.Result{{Code: "IR", Count: 3, CountTotal: 9}},
+	}
+
+	statsd := strings.Join(StatsdEncoder{}.Encode(sample), "\n")
+	for _, want := range []string{"conduit.connected_clients:12|g", "conduit.bytes_down:200|g", "conduit.clients_by_country.IR:3|g"} {
+		if !strings.Contains(statsd, want) {
+			t.Fatalf("statsd output missing %q:\n%s", want, statsd)
+		}
+	}
+
+	influx := InfluxEncoder{}.Encode(sample)
+	if len(influx) != 2 {
+		t.Fatalf("got %d influx lines, expected 2", len(influx))
+	}
+	if want := "conduit_geo,country=IR clients=3i,clients_total=9i,bytes_up=0i,bytes_down=0i 1700000000000000000"; influx[1] != want {
+		t.Fatalf("influx geo line = %q, expected %q", influx[1], want)
+	}
+}
+
+func TestUDPSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+
+	sink, err := Open(Config{Addr: pc.LocalAddr().String(), Format: "statsd"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Send(Sample{ConnectedClients: 4}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	buf := make([]byte, maxDatagramSize)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(buf[:n]), "conduit.connected_clients:4|g") {
+		t.Fatalf("unexpected datagram: %q", buf[:n])
+	}
+}